@@ -11,8 +11,28 @@ func main() {
 	// Get command line arguments
 	args := os.Args[1:]
 
-	// Execute git cocommit
-	if err := git.Cocommit(args); err != nil {
+	// Dispatch subcommands, otherwise execute git cocommit
+	var err error
+	switch {
+	case len(args) > 0 && args[0] == "authors":
+		err = git.Authors(args[1:])
+	case len(args) > 0 && args[0] == "hook":
+		err = git.Hook(args[1:])
+	case len(args) > 0 && args[0] == "with":
+		err = git.With(args[1:])
+	case len(args) > 0 && args[0] == "solo":
+		err = git.Solo(args[1:])
+	case len(args) > 0 && args[0] == "status":
+		err = git.Status(args[1:])
+	case len(args) > 0 && args[0] == "rewrite":
+		err = git.Rewrite(args[1:])
+	case len(args) > 0 && args[0] == "editor":
+		err = git.Editor(args[1:])
+	default:
+		err = git.Cocommit(args)
+	}
+
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -6,10 +6,13 @@ require github.com/google/go-github/v58 v58.0.0
 
 require golang.org/x/oauth2 v0.18.0
 
+require golang.org/x/term v0.25.0
+
 require (
 	github.com/golang/protobuf v1.5.3 // indirect
 	github.com/google/go-querystring v1.1.0 // indirect
 	golang.org/x/net v0.22.0 // indirect
+	golang.org/x/sys v0.26.0 // indirect
 	google.golang.org/appengine v1.6.7 // indirect
 	google.golang.org/protobuf v1.31.0 // indirect
 )
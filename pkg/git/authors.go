@@ -0,0 +1,278 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/mail"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// repoAuthorsFile is the repository-level alias registry, relative to the work tree root
+	repoAuthorsFile = ".cocommit-authors"
+)
+
+// authorRegistry maps short handles to full "Name <email>" identities
+type authorRegistry map[string]string
+
+// globalAuthorsPath returns the path of the per-user alias registry
+// ($XDG_CONFIG_HOME/cocommit/authors, defaulting to ~/.config/cocommit/authors)
+func globalAuthorsPath() (string, error) {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		configDir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configDir, "cocommit", "authors"), nil
+}
+
+// repoAuthorsPath returns the path of the alias registry in the current repository
+func repoAuthorsPath() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.New("not inside a git work tree")
+	}
+	return filepath.Join(strings.TrimSpace(string(out)), repoAuthorsFile), nil
+}
+
+// parseIdentity validates a "Name <email>" identity and returns it in normalized form
+func parseIdentity(identity string) (string, error) {
+	address, err := mail.ParseAddress(strings.TrimSpace(identity))
+	if err != nil || address.Name == "" {
+		return "", fmt.Errorf("invalid identity '%s': expected \"Name <email>\"", identity)
+	}
+	return formatIdentity(address.Name, address.Address), nil
+}
+
+// formatIdentity formats a name and an email address as "Name <email>"
+// The name is quoted when it would not parse back unchanged, e.g. when it contains a comma.
+func formatIdentity(name, address string) string {
+	identity := fmt.Sprintf("%s <%s>", name, address)
+	if parsed, err := mail.ParseAddress(identity); err == nil && parsed.Name == name {
+		return identity
+	}
+	quoted := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(name)
+	return fmt.Sprintf(`"%s" <%s>`, quoted, address)
+}
+
+// isLiteralIdentity reports whether spec is a full "Name <email>" identity or a bare email address
+// rather than a handle
+// Team handles ("@org/team") and prefixed handles ("gitlab:@group/team") are not email addresses.
+func isLiteralIdentity(spec string) bool {
+	spec = strings.TrimSpace(spec)
+	if strings.Contains(spec, "<") {
+		return true
+	}
+	return strings.Contains(spec, "@") && !strings.HasPrefix(spec, "@") && !strings.Contains(spec, ":")
+}
+
+// parseLiteralIdentity validates a literal co-author as an RFC 5322 address
+// and returns it in "Name <email>" form, which parses back to the same identity
+// A bare email address is named after its local part.
+func parseLiteralIdentity(spec string) (string, error) {
+	address, err := mail.ParseAddress(strings.TrimSpace(spec))
+	if err != nil {
+		return "", fmt.Errorf("invalid co-author '%s': expected a username, \"Name <email>\" or an email address", spec)
+	}
+	name := address.Name
+	if name == "" {
+		name, _, _ = strings.Cut(address.Address, "@")
+	}
+	return formatIdentity(name, address.Address), nil
+}
+
+// loadAuthorsFile reads an alias registry file
+// Each line has the form "handle Name <email>"; blank lines and lines starting with '#' are ignored.
+// A missing file is treated as an empty registry.
+func loadAuthorsFile(path string) (authorRegistry, error) {
+	registry := authorRegistry{}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return registry, nil
+		}
+		return nil, fmt.Errorf("failed to open authors file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		handle, identity, found := strings.Cut(line, " ")
+		if !found {
+			return nil, fmt.Errorf("%s:%d: expected \"handle Name <email>\"", path, lineNumber)
+		}
+		identity, err = parseIdentity(identity)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNumber, err)
+		}
+		registry[strings.ToLower(handle)] = identity
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read authors file: %w", err)
+	}
+
+	return registry, nil
+}
+
+// save writes the registry to path, sorted by handle
+func (r authorRegistry) save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create authors directory: %w", err)
+	}
+
+	var b strings.Builder
+	b.WriteString("# git-cocommit author aliases: handle Name <email>\n")
+	for _, handle := range r.handles() {
+		fmt.Fprintf(&b, "%s %s\n", handle, r[handle])
+	}
+
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write authors file: %w", err)
+	}
+	return nil
+}
+
+// handles returns the registered handles in sorted order
+func (r authorRegistry) handles() []string {
+	handles := make([]string, 0, len(r))
+	for handle := range r {
+		handles = append(handles, handle)
+	}
+	sort.Strings(handles)
+	return handles
+}
+
+// lookup returns the identity registered for handle (case-insensitive)
+func (r authorRegistry) lookup(handle string) (string, bool) {
+	identity, ok := r[strings.ToLower(handle)]
+	return identity, ok
+}
+
+// loadAuthorRegistry loads the global registry and overlays the repository registry on top of it
+func loadAuthorRegistry() (authorRegistry, error) {
+	registry := authorRegistry{}
+
+	paths := []string{}
+	if path, err := globalAuthorsPath(); err == nil {
+		paths = append(paths, path)
+	}
+	if path, err := repoAuthorsPath(); err == nil {
+		paths = append(paths, path)
+	}
+
+	for _, path := range paths {
+		entries, err := loadAuthorsFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for handle, identity := range entries {
+			registry[handle] = identity
+		}
+	}
+
+	return registry, nil
+}
+
+// Authors manages the co-author alias registry
+// Usage: git cocommit authors add [--repo] <handle> <Name <email>>
+//
+//	git cocommit authors remove [--repo] <handle>
+//	git cocommit authors list
+func Authors(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: git cocommit authors add|remove|list")
+	}
+
+	subcommand, args := args[0], args[1:]
+
+	// Select the registry file to modify
+	useRepo := false
+	if len(args) > 0 && args[0] == "--repo" {
+		useRepo = true
+		args = args[1:]
+	}
+	var path string
+	var err error
+	if useRepo {
+		path, err = repoAuthorsPath()
+	} else {
+		path, err = globalAuthorsPath()
+	}
+	if err != nil {
+		return err
+	}
+
+	switch subcommand {
+	case "add":
+		if len(args) < 2 {
+			return errors.New("usage: git cocommit authors add [--repo] <handle> <Name <email>>")
+		}
+		identity, err := parseIdentity(strings.Join(args[1:], " "))
+		if err != nil {
+			return err
+		}
+		registry, err := loadAuthorsFile(path)
+		if err != nil {
+			return err
+		}
+		registry[strings.ToLower(args[0])] = identity
+		if err := registry.save(path); err != nil {
+			return err
+		}
+		fmt.Printf("Added %s: %s\n", strings.ToLower(args[0]), identity)
+		return nil
+
+	case "remove":
+		if len(args) != 1 {
+			return errors.New("usage: git cocommit authors remove [--repo] <handle>")
+		}
+		registry, err := loadAuthorsFile(path)
+		if err != nil {
+			return err
+		}
+		handle := strings.ToLower(args[0])
+		if _, ok := registry[handle]; !ok {
+			return fmt.Errorf("no author registered as '%s' in %s", args[0], path)
+		}
+		delete(registry, handle)
+		if err := registry.save(path); err != nil {
+			return err
+		}
+		fmt.Printf("Removed %s\n", handle)
+		return nil
+
+	case "list":
+		registry, err := loadAuthorRegistry()
+		if err != nil {
+			return err
+		}
+		if len(registry) == 0 {
+			fmt.Println("No authors registered")
+			return nil
+		}
+		for _, handle := range registry.handles() {
+			fmt.Printf("%s\t%s\n", handle, registry[handle])
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown authors subcommand '%s'", subcommand)
+	}
+}
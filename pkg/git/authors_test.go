@@ -0,0 +1,266 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseIdentity(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "Name and email",
+			identity: "Alice Smith <alice@example.com>",
+			want:     "Alice Smith <alice@example.com>",
+			wantErr:  false,
+		},
+		{
+			name:     "Surrounding whitespace",
+			identity: "  Bob <bob@example.com> ",
+			want:     "Bob <bob@example.com>",
+			wantErr:  false,
+		},
+		{
+			name:     "Name that needs quoting",
+			identity: `"Doe, John" <john@example.com>`,
+			want:     `"Doe, John" <john@example.com>`,
+			wantErr:  false,
+		},
+		{
+			name:     "Email without name",
+			identity: "alice@example.com",
+			want:     "",
+			wantErr:  true,
+		},
+		{
+			name:     "Malformed email",
+			identity: "Alice <alice>",
+			want:     "",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseIdentity(tt.identity)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseIdentity() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseIdentity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatIdentity(t *testing.T) {
+	tests := []struct {
+		name    string
+		display string
+		want    string
+	}{
+		{
+			name:    "Plain name",
+			display: "Alice Smith",
+			want:    "Alice Smith <alice@example.com>",
+		},
+		{
+			name:    "Non-ASCII name",
+			display: "José Müller",
+			want:    "José Müller <alice@example.com>",
+		},
+		{
+			name:    "Name with a comma",
+			display: "Doe, John",
+			want:    `"Doe, John" <alice@example.com>`,
+		},
+		{
+			name:    "Name with a parenthesis",
+			display: "Alice Smith (alice)",
+			want:    `"Alice Smith (alice)" <alice@example.com>`,
+		},
+		{
+			name:    "Name with quotes",
+			display: `Alice "Al" Smith`,
+			want:    `"Alice \"Al\" Smith" <alice@example.com>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatIdentity(tt.display, "alice@example.com")
+			if got != tt.want {
+				t.Errorf("formatIdentity() = %v, want %v", got, tt.want)
+			}
+			if parsed, err := parseIdentity(got); err != nil || parsed != got {
+				t.Errorf("parseIdentity(%v) = %v, %v", got, parsed, err)
+			}
+		})
+	}
+}
+
+func TestIsLiteralIdentity(t *testing.T) {
+	tests := []struct {
+		spec string
+		want bool
+	}{
+		{spec: "alice", want: false},
+		{spec: "gitlab:alice", want: false},
+		{spec: "@myorg/platform", want: false},
+		{spec: "gitlab:@mygroup/subgroup", want: false},
+		{spec: "Alice <alice@example.com>", want: true},
+		{spec: "<alice@example.com>", want: true},
+		{spec: "alice@example.com", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			if got := isLiteralIdentity(tt.spec); got != tt.want {
+				t.Errorf("isLiteralIdentity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLiteralIdentity(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "Name and email",
+			spec:    " Alice Smith <alice@example.com> ",
+			want:    "Alice Smith <alice@example.com>",
+			wantErr: false,
+		},
+		{
+			name:    "Quoted name",
+			spec:    `"Doe, John" <john@example.com>`,
+			want:    `"Doe, John" <john@example.com>`,
+			wantErr: false,
+		},
+		{
+			name:    "Bare email",
+			spec:    "carol.jones@example.com",
+			want:    "carol.jones <carol.jones@example.com>",
+			wantErr: false,
+		},
+		{
+			name:    "Email only in angle brackets",
+			spec:    "<carol@example.com>",
+			want:    "carol <carol@example.com>",
+			wantErr: false,
+		},
+		{
+			name:    "Missing domain",
+			spec:    "Alice <alice>",
+			want:    "",
+			wantErr: true,
+		},
+		{
+			name:    "Unclosed angle bracket",
+			spec:    "Alice <alice@example.com",
+			want:    "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLiteralIdentity(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseLiteralIdentity() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseLiteralIdentity() = %v, want %v", got, tt.want)
+			}
+			if tt.wantErr {
+				return
+			}
+			if again, err := parseLiteralIdentity(got); err != nil || again != got {
+				t.Errorf("parseLiteralIdentity(%v) = %v, %v, want it unchanged", got, again, err)
+			}
+		})
+	}
+}
+
+func TestLoadAuthorsFile(t *testing.T) {
+	t.Run("Missing file", func(t *testing.T) {
+		registry, err := loadAuthorsFile(filepath.Join(t.TempDir(), "authors"))
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if len(registry) != 0 {
+			t.Errorf("Expected empty registry, got %v", registry)
+		}
+	})
+
+	t.Run("Valid file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "authors")
+		content := "# comment\n\nalice Alice Smith <alice@example.com>\nBob Bob Jones <bob@example.com>\n"
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		registry, err := loadAuthorsFile(path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if identity, ok := registry.lookup("ALICE"); !ok || identity != "Alice Smith <alice@example.com>" {
+			t.Errorf("lookup(ALICE) = %v, %v", identity, ok)
+		}
+		if identity, ok := registry.lookup("bob"); !ok || identity != "Bob Jones <bob@example.com>" {
+			t.Errorf("lookup(bob) = %v, %v", identity, ok)
+		}
+	})
+
+	t.Run("Invalid line", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "authors")
+		if err := os.WriteFile(path, []byte("alice\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := loadAuthorsFile(path); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
+
+func TestAuthorRegistrySave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cocommit", "authors")
+	doe, err := parseIdentity(`"Doe, John" <john@example.com>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := authorRegistry{
+		"bob":   "Bob Jones <bob@example.com>",
+		"alice": "Alice Smith <alice@example.com>",
+		"doe":   doe,
+	}
+
+	if err := registry.save(path); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	loaded, err := loadAuthorsFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(loaded) != len(registry) {
+		t.Errorf("Expected %d entries, got %d", len(registry), len(loaded))
+	}
+	for handle, identity := range registry {
+		if loaded[handle] != identity {
+			t.Errorf("loaded[%s] = %v, want %v", handle, loaded[handle], identity)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// lineRange is a range of lines in a file, from start to end inclusive
+type lineRange struct {
+	start int
+	end   int
+}
+
+// ownership is how many of the touched lines an author owns
+type ownership struct {
+	author  string
+	lines   int
+	percent float64
+}
+
+// String formats the ownership for the selectors
+func (o ownership) String() string {
+	return fmt.Sprintf("%s (%.0f%%)", o.author, o.percent)
+}
+
+// parseStagedHunks parses the output of git diff --cached -U0 into the ranges of
+// pre-existing lines touched in each file
+// Changed and removed lines count as touched. For pure insertions, the line above
+// the insertion is counted, since its owner most likely knows the surrounding code.
+// New files have no previous owners and are skipped.
+func parseStagedHunks(diff string) map[string][]lineRange {
+	hunks := map[string][]lineRange{}
+	path := ""
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			path = ""
+		case strings.HasPrefix(line, "--- "):
+			path = parseDiffPath(strings.TrimPrefix(line, "--- "))
+		case strings.HasPrefix(line, "@@ ") && path != "":
+			// @@ -start[,count] +start[,count] @@
+			fields := strings.Fields(line)
+			if len(fields) < 2 || !strings.HasPrefix(fields[1], "-") {
+				continue
+			}
+			startText, countText, hasCount := strings.Cut(fields[1][1:], ",")
+			start, err := strconv.Atoi(startText)
+			if err != nil {
+				continue
+			}
+			count := 1
+			if hasCount {
+				if count, err = strconv.Atoi(countText); err != nil {
+					continue
+				}
+			}
+
+			if count == 0 {
+				// Pure insertion after line start
+				if start == 0 {
+					continue
+				}
+				count = 1
+			}
+			hunks[path] = append(hunks[path], lineRange{start: start, end: start + count - 1})
+		}
+	}
+	return hunks
+}
+
+// parseDiffPath parses the path of a "--- a/path" line, returning "" for /dev/null
+func parseDiffPath(value string) string {
+	// git ends the line with a tab when the path contains a space
+	value = strings.TrimSuffix(value, "\t")
+	if strings.HasPrefix(value, `"`) {
+		// git quotes paths with unusual characters
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+	}
+	if value == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(value, "a/")
+}
+
+// parseBlamePorcelain counts the lines owned by each author in the output of git blame --porcelain
+func parseBlamePorcelain(blame string) map[string]int {
+	counts := map[string]int{}
+	names := map[string]string{}
+	emails := map[string]string{}
+	commit := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(blame))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			// The content of a blamed line ends the entry
+			counts[names[commit]+" "+emails[commit]]++
+		case strings.HasPrefix(line, "author "):
+			names[commit] = strings.TrimPrefix(line, "author ")
+		case strings.HasPrefix(line, "author-mail "):
+			emails[commit] = strings.TrimPrefix(line, "author-mail ")
+		default:
+			// "<sha> <orig line> <final line> [<lines>]" starts an entry
+			if fields := strings.Fields(line); len(fields) >= 3 && len(fields[0]) >= 40 {
+				commit = fields[0]
+			}
+		}
+	}
+	return counts
+}
+
+// rankOwners ranks authors by the number of touched lines they own, leaving out excluded ones
+// Percentages are of all touched lines, including those owned by excluded authors.
+func rankOwners(counts map[string]int, excluded func(string) bool) []ownership {
+	total := 0
+	for _, lines := range counts {
+		total += lines
+	}
+
+	var owners []ownership
+	for author, lines := range counts {
+		if excluded(author) {
+			continue
+		}
+		owners = append(owners, ownership{
+			author:  author,
+			lines:   lines,
+			percent: float64(lines) * 100 / float64(total),
+		})
+	}
+
+	sort.Slice(owners, func(i, j int) bool {
+		if owners[i].lines != owners[j].lines {
+			return owners[i].lines > owners[j].lines
+		}
+		return owners[i].author < owners[j].author
+	})
+	return owners
+}
+
+// getStagedOwners blames the lines touched by the staged changes and ranks their authors
+func getStagedOwners() ([]ownership, error) {
+	currentUser, err := getCurrentGitUser()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("git", "diff", "--cached", "-U0", "--no-color", "--no-ext-diff", "--src-prefix=a/", "--dst-prefix=b/")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staged changes: %w", err)
+	}
+
+	counts := map[string]int{}
+	for path, ranges := range parseStagedHunks(string(out)) {
+		args := []string{"blame", "--porcelain"}
+		for _, r := range ranges {
+			args = append(args, "-L", fmt.Sprintf("%d,%d", r.start, r.end))
+		}
+		args = append(args, "HEAD", "--", path)
+
+		out, err := exec.Command("git", args...).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to blame %s: %w", path, err)
+		}
+		for author, lines := range parseBlamePorcelain(string(out)) {
+			counts[author] += lines
+		}
+	}
+
+	// git blame applies .mailmap to the authors
+	return rankOwners(counts, currentUserMatcher(currentUser)), nil
+}
+
+// selectStagedOwners lets the user select co-authors among the owners of the staged changes
+func selectStagedOwners() ([]string, error) {
+	owners, err := getStagedOwners()
+	if err != nil {
+		return nil, err
+	}
+	if len(owners) == 0 {
+		return nil, fmt.Errorf("no other authors found in the lines touched by the staged changes")
+	}
+
+	// Show the share of touched lines, but return the identities
+	items := make([]string, len(owners))
+	identities := map[string]string{}
+	for i, owner := range owners {
+		items[i] = owner.String()
+		identities[items[i]] = owner.author
+	}
+
+	selected, err := selectItems(items, "Select co-authors", "Owners of the lines you changed:")
+	if err != nil {
+		return nil, err
+	}
+
+	var coAuthors []string
+	for _, item := range selected {
+		coAuthors = append(coAuthors, identities[item])
+	}
+	return coAuthors, nil
+}
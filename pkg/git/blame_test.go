@@ -0,0 +1,156 @@
+package git
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParseStagedHunks(t *testing.T) {
+	diff := `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -3 +3 @@ func main() {
+-	old()
++	new()
+@@ -10,2 +10,0 @@ func helper() {
+-	a()
+-	b()
+@@ -20,0 +19,2 @@ func other() {
++	c()
++	d()
+diff --git a/new.go b/new.go
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1 @@
++package main
+diff --git "a/with space.go" "b/with space.go"
+index 4444444..5555555 100644
+--- "a/with space.go"
++++ "b/with space.go"
+@@ -0,0 +1 @@
++// Header
+@@ -5,3 +6,3 @@
+-x
+-y
+-z
++X
++Y
++Z
+`
+
+	want := map[string][]lineRange{
+		"main.go":       {{3, 3}, {10, 11}, {20, 20}},
+		"with space.go": {{5, 7}},
+	}
+
+	got := parseStagedHunks(diff)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseStagedHunks() = %v, want %v", got, want)
+	}
+}
+
+func TestParseDiffPath(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "Plain path", value: "a/main.go", want: "main.go"},
+		{name: "Path with a space", value: "a/sp ace.txt\t", want: "sp ace.txt"},
+		{name: "Quoted path", value: `"a/caf\303\251.txt"`, want: "café.txt"},
+		{name: "New file", value: "/dev/null", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseDiffPath(tt.value); got != tt.want {
+				t.Errorf("parseDiffPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBlamePorcelain(t *testing.T) {
+	blame := `fd1cf9e5b65f49dde75cbefcbe82ce87c3f1334d 1 1 2
+author Ann
+author-mail <ann@example.com>
+author-time 1700000000
+author-tz +0000
+summary one
+filename f
+	a
+fd1cf9e5b65f49dde75cbefcbe82ce87c3f1334d 2 2
+	b
+0a1b2c3d4e5f60718293a4b5c6d7e8f901234567 5 3 1
+author Ben
+author-mail <ben@example.com>
+author-time 1700000100
+author-tz +0000
+summary two
+previous fd1cf9e5b65f49dde75cbefcbe82ce87c3f1334d f
+filename f
+	author Not A Header
+`
+
+	want := map[string]int{
+		"Ann <ann@example.com>": 2,
+		"Ben <ben@example.com>": 1,
+	}
+
+	got := parseBlamePorcelain(blame)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseBlamePorcelain() = %v, want %v", got, want)
+	}
+}
+
+func TestRankOwners(t *testing.T) {
+	counts := map[string]int{
+		"Ann <ann@example.com>": 2,
+		"Ben <ben@example.com>": 5,
+		"Me <me@example.com>":   3,
+		"Cat <cat@example.com>": 2,
+	}
+
+	want := []ownership{
+		{author: "Ben <ben@example.com>", lines: 5, percent: 41.666666666666664},
+		{author: "Ann <ann@example.com>", lines: 2, percent: 16.666666666666668},
+		{author: "Cat <cat@example.com>", lines: 2, percent: 16.666666666666668},
+	}
+
+	got := rankOwners(counts, func(author string) bool {
+		return author == "Me <me@example.com>"
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rankOwners() = %v, want %v", got, want)
+	}
+	if s := got[0].String(); s != "Ben <ben@example.com> (42%)" {
+		t.Errorf("ownership.String() = %q, want %q", s, "Ben <ben@example.com> (42%)")
+	}
+}
+
+func TestGetStagedOwners(t *testing.T) {
+	newTestRepo(t)
+	if err := os.WriteFile("sp ace.txt", []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	testGit(t, "add", "sp ace.txt")
+	testGit(t, "-c", "user.name=Ann", "-c", "user.email=ann@example.com", "commit", "-q", "-m", "Add file")
+
+	if err := os.WriteFile("sp ace.txt", []byte("one\nTWO\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	testGit(t, "add", "sp ace.txt")
+
+	owners, err := getStagedOwners()
+	if err != nil {
+		t.Fatalf("getStagedOwners() error = %v", err)
+	}
+	want := []ownership{{author: "Ann <ann@example.com>", lines: 1, percent: 100}}
+	if !reflect.DeepEqual(owners, want) {
+		t.Errorf("getStagedOwners() = %v, want %v", owners, want)
+	}
+}
@@ -0,0 +1,170 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cleanupMode is how git commit cleans up the message (--cleanup, commit.cleanup)
+type cleanupMode string
+
+const (
+	// cleanupStrip removes comment lines and cleans up whitespace
+	cleanupStrip cleanupMode = "strip"
+	// cleanupWhitespace cleans up whitespace but keeps comment lines
+	cleanupWhitespace cleanupMode = "whitespace"
+	// cleanupVerbatim leaves the message as it is
+	cleanupVerbatim cleanupMode = "verbatim"
+	// cleanupScissors is whitespace, but also cuts the message at the scissors line when it is edited
+	cleanupScissors cleanupMode = "scissors"
+	// cleanupDefault is strip when the message is edited, and whitespace otherwise
+	cleanupDefault cleanupMode = "default"
+)
+
+// commentCharCandidates are tried in order when core.commentChar is "auto", like in git
+const commentCharCandidates = "#;@!$%^&|:"
+
+// parseCleanupMode parses a cleanup mode name
+func parseCleanupMode(value string) (cleanupMode, error) {
+	switch mode := cleanupMode(value); mode {
+	case "":
+		return cleanupDefault, nil
+	case cleanupStrip, cleanupWhitespace, cleanupVerbatim, cleanupScissors, cleanupDefault:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid cleanup mode '%s' (use strip, whitespace, verbatim, scissors or default)", value)
+	}
+}
+
+// getCleanupMode gets the cleanup mode from the --cleanup value, falling back to commit.cleanup
+func getCleanupMode(value string) (cleanupMode, error) {
+	if value == "" {
+		value = getGitConfig("commit.cleanup")
+	}
+	return parseCleanupMode(value)
+}
+
+// resolve returns the mode git applies, depending on whether the message is edited
+func (m cleanupMode) resolve(editing bool) cleanupMode {
+	switch {
+	case m == cleanupDefault && editing:
+		return cleanupStrip
+	case m == cleanupDefault, m == cleanupScissors && !editing:
+		return cleanupWhitespace
+	default:
+		return m
+	}
+}
+
+// cleanupMessage cleans up message like git commit does with mode
+func cleanupMessage(message string, mode cleanupMode, commentChar string, editing bool) string {
+	switch mode.resolve(editing) {
+	case cleanupVerbatim:
+		return message
+	case cleanupStrip:
+		return stripSpace(message, commentChar)
+	case cleanupScissors:
+		return stripSpace(cutScissors(message, commentChar), "")
+	default:
+		return stripSpace(message, "")
+	}
+}
+
+// stripSpace works like git stripspace: it removes trailing whitespace, leading and trailing
+// blank lines and repeated blank lines, and lines starting with commentPrefix unless it is empty.
+// Each remaining line ends with a newline.
+func stripSpace(message, commentPrefix string) string {
+	var b strings.Builder
+	empties := 0
+	for _, line := range strings.SplitAfter(message, "\n") {
+		if commentPrefix != "" && strings.HasPrefix(line, commentPrefix) {
+			continue
+		}
+		line = strings.TrimRight(line, " \t\n\r\v\f")
+		if line == "" {
+			empties++
+			continue
+		}
+		if empties > 0 && b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		empties = 0
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+// scissorsLine returns the line below which git ignores the message
+func scissorsLine(commentChar string) string {
+	return commentChar + " ------------------------ >8 ------------------------"
+}
+
+// cutScissors removes the scissors line and everything below it from message
+func cutScissors(message, commentChar string) string {
+	scissors := scissorsLine(commentChar) + "\n"
+	if strings.HasPrefix(message, scissors) {
+		return ""
+	}
+	if i := strings.Index(message, "\n"+scissors); i >= 0 {
+		return message[:i+1]
+	}
+	return message
+}
+
+// getCommentChar gets the string that starts comment lines in message
+// With core.commentChar set to "auto", git picks a character that does not start any line of message.
+func getCommentChar(message string) string {
+	switch commentChar := getGitConfig("core.commentChar"); commentChar {
+	case "":
+		return "#"
+	case "auto":
+		return autoCommentChar(message)
+	default:
+		return commentChar
+	}
+}
+
+// getPreparedCommentChar gets the string that starts comment lines in a commit message file prepared by git
+// With core.commentChar set to "auto", the original message is unknown, so the character is taken from
+// the comment block git appended.
+func getPreparedCommentChar(content string) string {
+	commentChar := getGitConfig("core.commentChar")
+	if commentChar != "auto" {
+		return getCommentChar(content)
+	}
+	return templateCommentChar(content)
+}
+
+// autoCommentChar returns the first candidate character that does not start a line of message, like git
+func autoCommentChar(message string) string {
+	used := map[byte]bool{}
+	for _, line := range strings.Split(message, "\n") {
+		if line != "" {
+			used[line[0]] = true
+		}
+	}
+	for i := 0; i < len(commentCharCandidates); i++ {
+		if !used[commentCharCandidates[i]] {
+			return string(commentCharCandidates[i])
+		}
+	}
+	return "#"
+}
+
+// templateCommentChar returns the comment character of a file prepared by git: the one starting
+// the scissors line if there is one, or else the last line, which git always makes a comment.
+// Falls back to "#".
+func templateCommentChar(content string) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	for _, line := range lines {
+		if len(line) > 0 && line == scissorsLine(line[:1]) && strings.Contains(commentCharCandidates, line[:1]) {
+			return line[:1]
+		}
+	}
+
+	last := lines[len(lines)-1]
+	if len(last) > 0 && strings.Contains(commentCharCandidates, last[:1]) {
+		return last[:1]
+	}
+	return "#"
+}
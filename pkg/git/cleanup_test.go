@@ -0,0 +1,167 @@
+package git
+
+import "testing"
+
+func TestCleanupMessage(t *testing.T) {
+	const scissors = "# ------------------------ >8 ------------------------"
+
+	tests := []struct {
+		name    string
+		message string
+		mode    cleanupMode
+		editing bool
+		want    string
+	}{
+		{
+			name:    "Strip removes comments and blank lines",
+			message: "\n\nSubject  \n\n\n\nBody\t\n# comment\n\n",
+			mode:    cleanupStrip,
+			want:    "Subject\n\nBody\n",
+		},
+		{
+			name:    "Strip only removes lines starting with the comment character",
+			message: "Subject\n\n  # indented\n#123 issue\n## Heading\n",
+			mode:    cleanupStrip,
+			want:    "Subject\n\n  # indented\n",
+		},
+		{
+			name:    "Comment lines do not count as blank lines",
+			message: "Subject\n# comment\nBody\n",
+			mode:    cleanupStrip,
+			want:    "Subject\nBody\n",
+		},
+		{
+			name:    "Whitespace keeps comments",
+			message: "Subject\n\n\n#123 issue\n## Heading  \n",
+			mode:    cleanupWhitespace,
+			want:    "Subject\n\n#123 issue\n## Heading\n",
+		},
+		{
+			name:    "Verbatim keeps everything",
+			message: "\nSubject  \n\n\n# comment\n",
+			mode:    cleanupVerbatim,
+			want:    "\nSubject  \n\n\n# comment\n",
+		},
+		{
+			name:    "Default strips when editing",
+			message: "Subject\n# comment\n",
+			mode:    cleanupDefault,
+			editing: true,
+			want:    "Subject\n",
+		},
+		{
+			name:    "Default keeps comments when not editing",
+			message: "Subject\n#123 issue\n",
+			mode:    cleanupDefault,
+			want:    "Subject\n#123 issue\n",
+		},
+		{
+			name:    "Scissors cuts when editing",
+			message: "Subject\n# comment\n" + scissors + "\ndiff --git a/a b/a\n",
+			mode:    cleanupScissors,
+			editing: true,
+			want:    "Subject\n# comment\n",
+		},
+		{
+			name:    "Scissors is whitespace when not editing",
+			message: "Subject\n\n\n" + scissors + "\n",
+			mode:    cleanupScissors,
+			want:    "Subject\n\n" + scissors + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cleanupMessage(tt.message, tt.mode, "#", tt.editing)
+			if got != tt.want {
+				t.Errorf("cleanupMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanupMessageCommentChar(t *testing.T) {
+	got := cleanupMessage("Subject\n\n#123 issue\n; comment\n", cleanupStrip, ";", false)
+	if want := "Subject\n\n#123 issue\n"; got != want {
+		t.Errorf("cleanupMessage() = %q, want %q", got, want)
+	}
+}
+
+func TestParseCleanupMode(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    cleanupMode
+		wantErr bool
+	}{
+		{"", cleanupDefault, false},
+		{"strip", cleanupStrip, false},
+		{"whitespace", cleanupWhitespace, false},
+		{"verbatim", cleanupVerbatim, false},
+		{"scissors", cleanupScissors, false},
+		{"default", cleanupDefault, false},
+		{"all", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseCleanupMode(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseCleanupMode(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseCleanupMode(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestAutoCommentChar(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{"Plain message", "Subject\n\nBody\n", "#"},
+		{"Line starting with #", "Subject\n\n#123 issue\n", ";"},
+		{"Lines starting with # and ;", "#1\n;2\n", "@"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := autoCommentChar(tt.message); got != tt.want {
+				t.Errorf("autoCommentChar() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateCommentChar(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "Status comment block",
+			content: "#123 issue\n\n; Please enter the commit message\n;\n",
+			want:    ";",
+		},
+		{
+			name:    "Scissors line with diff",
+			content: "#123 issue\n\n; ------------------------ >8 ------------------------\ndiff --git a/a b/a\n+added\n",
+			want:    ";",
+		},
+		{
+			name:    "No comments",
+			content: "Subject\n",
+			want:    "#",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := templateCommentChar(tt.content); got != tt.want {
+				t.Errorf("templateCommentChar() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
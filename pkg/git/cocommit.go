@@ -2,183 +2,200 @@ package git
 
 import (
 	"bufio"
-	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
-	"github.com/MITSUBOSHI/cocommit/pkg/github"
+	"github.com/MITSUBOSHI/cocommit/pkg/provider"
 )
 
 const (
-	coAuthoredByPrefix = "Co-Authored-By: "
+	coAuthoredByKey = "Co-Authored-By"
+	// dryRunMessageFile stands for the temporary commit message file in --dry-run output
+	dryRunMessageFile = "<message>"
 )
 
 // Cocommit executes git commit command with
 // adding Co-Authored-By: to the commit message
 func Cocommit(args []string) error {
-	// Get Co-Authored-By: information
-	coAuthors, err := getCoAuthors()
+	// Separate git-cocommit flags from git commit arguments
+	opts, args := parseOptions(args)
+
+	// --trailer options naming people are resolved like co-authors; git adds the others itself
+	key, err := getTrailerKey()
 	if err != nil {
 		return err
 	}
+	people, gitTrailers := splitTrailerOptions(opts.trailers, key)
 
-	// Prepare arguments for git commit command
-	commitArgs := []string{"commit"}
-	messageIndex := -1
-	hasMessageFlag := false
-
-	// Find the position of -m flag
-	for i, arg := range args {
-		if arg == "-m" && i+1 < len(args) {
-			messageIndex = i + 1
-			hasMessageFlag = true
+	if opts.printTrailers {
+		// Only print the trailers, for use in scripts and editor integrations
+		// Prompts would be captured with the trailers, so the co-authors have to be given.
+		opts.noPrompt = true
+		trailers, err := getTrailers(opts, key, people)
+		if err != nil {
+			return err
 		}
-		commitArgs = append(commitArgs, arg)
-	}
-
-	// If -m flag exists, modify the message
-	if hasMessageFlag && messageIndex != -1 {
-		// Add each coAuthors entry to the message
-		message := args[messageIndex] + "\n\n"
-		for _, coAuthor := range coAuthors {
-			message += coAuthoredByPrefix + coAuthor + "\n"
+		for _, t := range trailers {
+			fmt.Println(t)
 		}
-		commitArgs[messageIndex] = strings.TrimRight(message, "\n")
-
-		// Execute git commit command
-		cmd := exec.Command("git", commitArgs...)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		cmd.Stdin = os.Stdin
-
-		return cmd.Run()
-	} else {
-		// If no -m flag, implement editor flow
-		return handleEditorCommit(args, coAuthors)
+		return nil
 	}
-}
 
-// handleEditorCommit supports commit message editing using an editor
-func handleEditorCommit(args []string, coAuthors []string) error {
-	// Create a temporary commit message file
-	tempFile, err := os.CreateTemp("", "COMMIT_EDITMSG")
+	// Find the message source among the git commit arguments
+	parsed, err := parseCommitArgs(append(gitTrailers, args...))
 	if err != nil {
-		return fmt.Errorf("failed to create temporary commit message file: %w", err)
+		return err
 	}
-	defer os.Remove(tempFile.Name())
-
-	// Get current branch name
-	branchName := getCurrentGitBranch()
 
-	// Write commit message template
-	_, err = tempFile.WriteString(fmt.Sprintf("\n\n# Note: Co-Authored-By trailers will be automatically added to your commit message.\n\n# Please enter the commit message for your changes. Lines starting\n# with '#' will be ignored, and an empty message aborts the commit.\n#\n# On branch %s\n#\n", branchName))
+	// Read the message before prompting, since -F - consumes standard input
+	message, err := parsed.message()
 	if err != nil {
-		return fmt.Errorf("failed to write template to commit message file: %w", err)
+		return err
 	}
-	tempFile.Close()
-
-	// Open commit message file in the editor
-	editor := getEditor()
-	cmd := exec.Command(editor, tempFile.Name())
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-	err = cmd.Run()
+	cleanup, err := getCleanupMode(parsed.cleanup)
 	if err != nil {
-		return fmt.Errorf("failed to open editor: %w", err)
+		return err
 	}
 
-	// Read the edited commit message
-	messageContent, err := os.ReadFile(tempFile.Name())
+	// Get Co-Authored-By: information
+	trailers, err := getTrailers(opts, key, people)
 	if err != nil {
-		return fmt.Errorf("failed to read commit message: %w", err)
+		return err
 	}
 
-	// Remove comment lines and trim whitespace
-	message := cleanCommitMessage(string(messageContent))
-	if message == "" {
-		return errors.New("aborted commit due to empty commit message")
+	switch {
+	case parsed.fixup:
+		// git generates fixup and squash messages itself, so let git add the trailers
+		var extra []string
+		for _, m := range parsed.messages {
+			extra = append(extra, "-m", m)
+		}
+		for _, t := range trailers {
+			extra = append(extra, "--trailer", t.String())
+		}
+		if opts.dryRun {
+			printDryRun("", "", parsed.command(extra...))
+			return nil
+		}
+		return runGit(parsed.command(extra...))
+
+	case parsed.hasMessage():
+		// Clean up the message as git will, so the trailers are added after its last line,
+		// then add the trailers and pass it to git as a file
+		message = cleanupMessage(message, cleanup, getCommentChar(message), parsed.editing())
+		if strings.TrimSpace(message) == "" {
+			// Leave an empty message empty so git aborts the commit as usual
+			return commitWithMessage(parsed, message, opts.dryRun)
+		}
+		return commitWithMessage(parsed, mergeTrailers(message, trailers), opts.dryRun)
+
+	case parsed.amend && parsed.edit != nil && !*parsed.edit:
+		// git keeps the amended commit's message without opening the editor, so add the co-authors to it here
+		message, err := getCommitMessage("HEAD")
+		if err != nil {
+			return err
+		}
+		message = cleanupMessage(message, cleanup, getCommentChar(message), false)
+		return commitWithMessage(parsed, mergeTrailers(message, trailers), opts.dryRun)
+
+	default:
+		// If no message is given, let git prepare it (from the amended commit or commit.template) and open the editor
+		return commitWithEditor(parsed.command(), trailers, cleanup, opts.dryRun)
 	}
+}
 
-	// Add Co-Authored-By
-	message += "\n\n"
-	for _, coAuthor := range coAuthors {
-		message += coAuthoredByPrefix + coAuthor + "\n"
+// commitWithMessage executes git commit with message in place of the original message source
+// With dryRun, the message and the command are printed instead.
+func commitWithMessage(parsed *commitArgs, message string, dryRun bool) error {
+	messageFile := dryRunMessageFile
+	if !dryRun {
+		tempFile, err := os.CreateTemp("", "COMMIT_EDITMSG")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary commit message file: %w", err)
+		}
+		defer os.Remove(tempFile.Name())
+
+		_, err = tempFile.WriteString(message)
+		tempFile.Close()
+		if err != nil {
+			return fmt.Errorf("failed to write commit message file: %w", err)
+		}
+		messageFile = tempFile.Name()
 	}
-	message = strings.TrimRight(message, "\n")
 
-	// Update temporary file with modified message
-	err = os.WriteFile(tempFile.Name(), []byte(message), 0644)
+	extra := []string{"-F", messageFile}
+
+	// -C and -c also reuse the authorship of the commit
+	authorship, err := parsed.authorshipArgs()
 	if err != nil {
-		return fmt.Errorf("failed to update commit message file: %w", err)
+		return err
 	}
+	extra = append(extra, authorship...)
 
-	// Execute git commit command (read message from file)
-	commitArgs := []string{"commit"}
-	for _, arg := range args {
-		// Do not use -m flag
-		if arg != "-m" && !strings.HasPrefix(arg, "-m") {
-			commitArgs = append(commitArgs, arg)
-		}
+	// -c opens the editor on the reused message
+	if parsed.reedit != "" && parsed.edit == nil {
+		extra = append(extra, "-e")
 	}
-	commitArgs = append(commitArgs, "-F", tempFile.Name())
 
-	cmd = exec.Command("git", commitArgs...)
+	if dryRun {
+		printDryRun("Commit message:", message, parsed.command(extra...))
+		return nil
+	}
+	return runGit(parsed.command(extra...))
+}
+
+// runGit executes a git command attached to the current terminal, with env added to its environment
+// The prepare-commit-msg hook installed by git cocommit is told to stay out of the way,
+// since the co-authors have already been added.
+func runGit(args []string, env ...string) error {
+	cmd := exec.Command("git", args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
+	cmd.Env = append(append(os.Environ(), hookSkipEnv+"=1"), env...)
 
 	return cmd.Run()
 }
 
-// cleanCommitMessage removes comment lines from commit message and trims excess whitespace
-func cleanCommitMessage(message string) string {
-	var lines []string
-	for _, line := range strings.Split(message, "\n") {
-		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
-			lines = append(lines, line)
-		}
+// printDryRun shows what git cocommit would do: text under heading, if any, and the git command
+// with the environment runGit would run it with
+func printDryRun(heading, text string, args []string, env ...string) {
+	if heading != "" {
+		fmt.Println(heading)
+		fmt.Println(strings.Trim(text, "\n"))
+		fmt.Println()
 	}
-	return strings.TrimSpace(strings.Join(lines, "\n"))
+	fmt.Println("Command:")
+	fmt.Println(formatEnv(append([]string{hookSkipEnv + "=1"}, env...)) + formatCommand(append([]string{"git"}, args...)))
 }
 
-// getEditor gets the editor to use
-func getEditor() string {
-	if editor := os.Getenv("GIT_EDITOR"); editor != "" {
-		return editor
-	}
-	if editor := os.Getenv("VISUAL"); editor != "" {
-		return editor
+// formatEnv formats "NAME=value" environment entries as shell assignments preceding a command
+func formatEnv(env []string) string {
+	var b strings.Builder
+	for _, entry := range env {
+		name, value, _ := strings.Cut(entry, "=")
+		b.WriteString(name + "=" + formatCommand([]string{value}) + " ")
 	}
-	if editor := os.Getenv("EDITOR"); editor != "" {
-		return editor
-	}
-
-	// Get editor from git config
-	cmd := exec.Command("git", "config", "--get", "core.editor")
-	output, err := cmd.Output()
-	if err == nil && len(output) > 0 {
-		return strings.TrimSpace(string(output))
-	}
-
-	// Default editor
-	return "vi"
+	return b.String()
 }
 
-// readYesNo reads a y/n input
-func readYesNo(prompt string) (bool, error) {
-	fmt.Print(prompt + " (y/n): ")
-	reader := bufio.NewReader(os.Stdin)
-	input, err := reader.ReadString('\n')
-	if err != nil {
-		return false, err
+// formatCommand joins args into a shell command, quoting the arguments that need it
+func formatCommand(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = arg
+		if arg == "" || strings.ContainsFunc(arg, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_=+./:,@%", r))
+		}) {
+			quoted[i] = shellQuote(arg)
+		}
 	}
-
-	input = strings.ToLower(strings.TrimSpace(input))
-	return input == "y" || input == "yes", nil
+	return strings.Join(quoted, " ")
 }
 
 // getCurrentGitUser gets the current Git user information (name and email address)
@@ -213,85 +230,37 @@ func getCurrentGitUserImpl() (string, error) {
 var getCurrentGitUserFunc = getCurrentGitUserImpl
 
 // getGitAuthors gets unique author information from the Git commit history
-// excluding the executor's own account
-func getGitAuthors() ([]string, error) {
+// The authors are ranked with the most likely co-authors first, and limited by --since and --max.
+func getGitAuthors(opts options) ([]string, error) {
 	// Get current user information
 	currentUser, err := getCurrentGitUser()
 	if err != nil {
 		return nil, err
 	}
 
-	// Get author list with git command
-	cmd := exec.Command("git", "log", "--format=%an <%ae>")
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	err = cmd.Run()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get git authors: %w", err)
-	}
-
-	// Split results by line, remove duplicates and current user
-	lines := strings.Split(out.String(), "\n")
-	uniqueAuthors := make(map[string]bool)
-	var authors []string
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		// Add only if not empty, not duplicate, and not current user
-		if line != "" && !uniqueAuthors[line] && line != currentUser {
-			uniqueAuthors[line] = true
-			authors = append(authors, line)
-		}
-	}
-
-	return authors, nil
-}
-
-// isPecoAvailable checks if peco is available on the system
-func isPecoAvailable() bool {
-	_, err := exec.LookPath("peco")
-	return err == nil
-}
-
-// selectWithPeco selects items using incremental search with peco
-func selectWithPeco(items []string, prompt string) ([]string, error) {
-	// Write choices to a temporary file
-	tempFile, err := os.CreateTemp("", "cocommit-peco")
+	since, max, err := getHistoryLimits(opts)
 	if err != nil {
 		return nil, err
 	}
-	defer os.Remove(tempFile.Name())
-
-	for _, item := range items {
-		fmt.Fprintln(tempFile, item)
-	}
-	tempFile.Close()
 
-	// Execute peco command
-	cmd := exec.Command("peco", "--prompt", prompt)
-	cmd.Stdin, err = os.Open(tempFile.Name())
-	if err != nil {
-		return nil, err
-	}
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = os.Stderr
-
-	err = cmd.Run()
+	commits, err := readHistory(since)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get selection results
-	selected := strings.Split(strings.TrimSpace(out.String()), "\n")
-	var result []string
-	for _, s := range selected {
-		if s != "" {
-			result = append(result, s)
+	// Remove current user, also under the names .mailmap maps to them
+	isCurrentUser := currentUserMatcher(currentUser)
+	var authors []string
+	for _, author := range rankAuthors(commits, time.Now()) {
+		if !isCurrentUser(author) {
+			authors = append(authors, author)
 		}
 	}
 
-	return result, nil
+	if max > 0 && len(authors) > max {
+		authors = authors[:max]
+	}
+	return authors, nil
 }
 
 // selectFromList selects items in a standard way from a list
@@ -339,133 +308,155 @@ func selectFromList(items []string, prompt string) ([]string, error) {
 	return selected, nil
 }
 
-// getCoAuthors gets Co-Authors information
-// Gets GitHub usernames from GIT_COAUTHORS environment variable or standard input,
-// and auto-completes email addresses using the GitHub API
-func getCoAuthors() ([]string, error) {
+// splitCoAuthors splits a comma-separated list of usernames into a slice
+// Commas inside double quotes or angle brackets belong to an identity, as in "Doe, John" <john@example.com>.
+func splitCoAuthors(value string) []string {
 	var usernames []string
-	var result []string
-
-	// Try to get from environment variable
-	if coAuthors := os.Getenv("GIT_COAUTHORS"); coAuthors != "" {
-		// Split comma-separated usernames into slice
-		for _, username := range strings.Split(coAuthors, ",") {
-			username = strings.TrimSpace(username)
-			if username != "" {
-				usernames = append(usernames, username)
-			}
+	add := func(username string) {
+		username = strings.TrimSpace(username)
+		if username != "" {
+			usernames = append(usernames, username)
 		}
-	} else {
-		// Select input method
-		reader := bufio.NewReader(os.Stdin)
+	}
+
+	start := 0
+	quoted, bracketed := false, false
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case c == '\\' && quoted:
+			// Skip the escaped character
+			i++
+		case c == '"':
+			quoted = !quoted
+		case c == '<' && !quoted:
+			bracketed = true
+		case c == '>' && !quoted:
+			bracketed = false
+		case c == ',' && !quoted && !bracketed:
+			add(value[start:i])
+			start = i + 1
+		}
+	}
+	add(value[start:])
+	return usernames
+}
 
-		fmt.Println("Select co-author input method:")
-		fmt.Println("1. Manual input")
-		fmt.Println("2. Select from Git history")
-		fmt.Print("Enter your choice (1-2): ")
+// getTrailers gets the trailers to add: one per co-author under key, then one per person given with --trailer
+func getTrailers(opts options, key string, people []trailer) ([]trailer, error) {
+	// People given with --trailer can stand in for the co-authors, so only ask for co-authors without them
+	coAuthors, err := getCoAuthors(opts, len(people) == 0)
+	if err != nil {
+		return nil, err
+	}
+	trailers := coAuthorTrailers(key, coAuthors)
 
-		// Read input using bufio.Reader
-		input, err := reader.ReadString('\n')
+	for _, person := range people {
+		identities, err := resolveCoAuthors(splitCoAuthors(person.value), opts)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read input: %w", err)
+			return nil, err
 		}
+		trailers = append(trailers, coAuthorTrailers(person.key, identities)...)
+	}
+	return trailers, nil
+}
 
-		input = strings.TrimSpace(input)
-		choice := 1 // Default is manual input
+// getCoAuthors gets Co-Authors information
+// Gets GitHub usernames from the --with flags, the GIT_COAUTHORS environment variable or the pairing session,
+// resolves registered aliases locally and auto-completes the rest using the GitHub API.
+// Otherwise the co-authors are chosen interactively if prompt is set and the user can be prompted.
+func getCoAuthors(opts options, prompt bool) ([]string, error) {
+	var usernames []string
 
-		// Convert to number
-		if input == "2" {
-			choice = 2
-		}
+	// Try the command line first, then the environment variable, then the pairing session
+	if len(opts.with) > 0 {
+		usernames = opts.with
+	} else if coAuthors := os.Getenv("GIT_COAUTHORS"); coAuthors != "" {
+		usernames = splitCoAuthors(coAuthors)
+	} else if s, err := loadSession(); err != nil {
+		return nil, err
+	} else if s != nil {
+		return s.identities(opts)
+	} else if !prompt {
+		return nil, nil
+	} else if !opts.canPrompt() {
+		// Reading standard input would hang in scripts and GUIs
+		return nil, errors.New("no co-authors given and cannot prompt for them: use --with <username>, GIT_COAUTHORS or 'git cocommit with'")
+	} else {
+		// Build the list interactively
+		return promptCoAuthors(opts)
+	}
 
-		if choice == 1 {
-			// Get multiple users from standard input
-			for {
-				// Prompt for user input
-				fmt.Print("Enter GitHub username: ")
-				input, err := reader.ReadString('\n')
-				if err != nil {
-					return nil, err
-				}
-
-				// Validate input
-				username := strings.TrimSpace(input)
-				if username == "" {
-					// Error if no one is specified
-					if len(usernames) == 0 {
-						return nil, errors.New("at least one GitHub username is required")
-					}
-					break
-				}
-
-				usernames = append(usernames, username)
-
-				// Confirm adding additional users
-				more, err := readYesNo("More co-author?")
-				if err != nil {
-					return nil, err
-				}
-
-				if !more {
-					break
-				}
-			}
-		} else {
-			// Get Author information from Git history
-			authors, err := getGitAuthors()
-			if err != nil {
-				return nil, err
-			}
+	// If no username is specified
+	if len(usernames) == 0 {
+		return nil, errors.New("at least one GitHub username is required")
+	}
 
-			if len(authors) == 0 {
-				return nil, errors.New("no author information found in Git history")
-			}
+	return resolveCoAuthors(usernames, opts)
+}
 
-			// Select from Author information
-			var selected []string
-			if isPecoAvailable() {
-				// Select with incremental search using peco
-				selected, err = selectWithPeco(authors, "Select co-authors")
-			} else {
-				// Standard selection method
-				selected, err = selectFromList(authors, "Available co-authors from Git history:")
-			}
+// resolveCoAuthors resolves usernames, aliases and team handles into "Name <email>" identities
+// Literal identities and email addresses are validated and used as is.
+func resolveCoAuthors(usernames []string, opts options) ([]string, error) {
+	// Registered aliases take precedence over GitHub lookups
+	registry, err := loadAuthorRegistry()
+	if err != nil {
+		return nil, err
+	}
 
-			if err != nil {
+	namePolicy, err := getNamePolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the providers for handles that are neither literal identities nor registered aliases
+	providers := newProviderSet(opts)
+	var remoteHandles []string
+	for _, username := range usernames {
+		if isLiteralIdentity(username) {
+			// Reject malformed identities before calling any provider
+			if _, err := parseLiteralIdentity(username); err != nil {
 				return nil, err
 			}
-
-			// Use selected Author information
-			return selected, nil
+			continue
 		}
+		if _, ok := registry.lookup(username); !ok {
+			remoteHandles = append(remoteHandles, username)
+		}
+	}
+	if err := providers.prepare(remoteHandles); err != nil {
+		return nil, err
 	}
 
-	// If no username is specified
-	if len(usernames) == 0 {
-		return nil, errors.New("at least one GitHub username is required")
+	// Replace team handles with their members
+	usernames, teamMembers, err := expandTeams(usernames, providers, opts.canPrompt())
+	if err != nil {
+		return nil, err
+	}
+	if err := providers.prepare(usernames); err != nil {
+		return nil, err
 	}
 
-	// Get email address for each username and create Co-Authored-By format string
-	for _, username := range usernames {
-		email, err := github.GetUserEmail(username)
+	// Get email address for each username concurrently and create Co-Authored-By format strings
+	coAuthors, err := resolveUsernames(usernames, func(ctx context.Context, username string) (string, error) {
+		if isLiteralIdentity(username) {
+			return parseLiteralIdentity(username)
+		}
+		if identity, ok := registry.lookup(username); ok {
+			return identity, nil
+		}
+
+		p, handle := providers.get(username)
+		identity, err := p.Resolve(ctx, handle)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get GitHub user information for '%s': %w", username, err)
+			return "", err
 		}
 
 		// Create Co-Authored-By format string
-		result = append(result, github.FormatCoAuthor(username, email))
-	}
-
-	return result, nil
-}
-
-// getCurrentGitBranch gets the current Git branch name
-func getCurrentGitBranch() string {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	out, err := cmd.Output()
+		return provider.FormatCoAuthor(identity.DisplayName(namePolicy), identity.Email), nil
+	})
 	if err != nil {
-		// Return default value if error occurs
-		return "unknown"
+		return nil, err
 	}
-	return strings.TrimSpace(string(out))
+
+	return excludeCurrentUser(usernames, coAuthors, teamMembers), nil
 }
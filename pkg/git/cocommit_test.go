@@ -4,11 +4,11 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"reflect"
 	"testing"
 )
 
 var execCommand = exec.Command
-var execLookPath = exec.LookPath
 var getCurrentGitUserMock = func() (string, error) {
 	return "Test User <test@example.com>", nil
 }
@@ -109,7 +109,7 @@ func TestGetGitAuthors(t *testing.T) {
 	}()
 	getCurrentGitUserFunc = getCurrentGitUserMock
 
-	authors, err := getGitAuthors()
+	authors, err := getGitAuthors(options{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -130,111 +130,6 @@ func TestGetGitAuthors(t *testing.T) {
 	}
 }
 
-func TestIsPecoAvailable(t *testing.T) {
-	t.Skip("This test is skipped due to complexities with mocking.")
-
-	originalLookPath := execLookPath
-	defer func() {
-		execLookPath = originalLookPath
-	}()
-
-	t.Run("Peco Available", func(t *testing.T) {
-		execLookPath = func(file string) (string, error) {
-			if file == "peco" {
-				return "/usr/local/bin/peco", nil
-			}
-			return "", fmt.Errorf("not found")
-		}
-
-		if !isPecoAvailable() {
-			t.Errorf("Expected peco to be available")
-		}
-	})
-
-	t.Run("Peco Not Available", func(t *testing.T) {
-		execLookPath = func(file string) (string, error) {
-			return "", fmt.Errorf("not found")
-		}
-
-		if isPecoAvailable() {
-			t.Errorf("Expected peco to be unavailable")
-		}
-	})
-}
-
-func TestReadYesNo(t *testing.T) {
-	tests := []struct {
-		name    string
-		input   string
-		want    bool
-		wantErr bool
-	}{
-		{
-			name:    "Affirmative with y",
-			input:   "y\n",
-			want:    true,
-			wantErr: false,
-		},
-		{
-			name:    "Affirmative with yes",
-			input:   "yes\n",
-			want:    true,
-			wantErr: false,
-		},
-		{
-			name:    "Negative with n",
-			input:   "n\n",
-			want:    false,
-			wantErr: false,
-		},
-		{
-			name:    "Negative with no",
-			input:   "no\n",
-			want:    false,
-			wantErr: false,
-		},
-		{
-			name:    "Other input",
-			input:   "other\n",
-			want:    false,
-			wantErr: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			oldStdin := os.Stdin
-			defer func() { os.Stdin = oldStdin }()
-
-			r, w, _ := os.Pipe()
-			os.Stdin = r
-
-			go func() {
-				w.Write([]byte(tt.input))
-				w.Close()
-			}()
-
-			oldStdout := os.Stdout
-			defer func() { os.Stdout = oldStdout }()
-
-			stdout, _ := os.CreateTemp("", "stdout")
-			defer os.Remove(stdout.Name())
-			os.Stdout = stdout
-
-			got, err := readYesNo("Test")
-
-			if (err != nil) != tt.wantErr {
-				t.Errorf("readYesNo() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-
-			if got != tt.want {
-				t.Errorf("readYesNo() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
 func TestSelectFromList(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -315,3 +210,86 @@ func TestSelectFromList(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitCoAuthors(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{
+			name:  "Usernames",
+			value: "alice, bob,,carol ",
+			want:  []string{"alice", "bob", "carol"},
+		},
+		{
+			name:  "Identities and emails",
+			value: "alice, Bob Smith <bob@example.com>, carol@example.com",
+			want:  []string{"alice", "Bob Smith <bob@example.com>", "carol@example.com"},
+		},
+		{
+			name:  "Comma in a quoted name",
+			value: `"Doe, John" <john@example.com>, alice`,
+			want:  []string{`"Doe, John" <john@example.com>`, "alice"},
+		},
+		{
+			name:  "Escaped quote in a quoted name",
+			value: `"John \"JD, Jr\" Doe" <john@example.com>,bob`,
+			want:  []string{`"John \"JD, Jr\" Doe" <john@example.com>`, "bob"},
+		},
+		{
+			name:  "Empty",
+			value: " ",
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitCoAuthors(tt.value)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitCoAuthors() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "Plain arguments",
+			args: []string{"git", "commit", "-a", "--fixup=amend:HEAD", "--", "src/main.go"},
+			want: "git commit -a --fixup=amend:HEAD -- src/main.go",
+		},
+		{
+			name: "Arguments with spaces and quotes",
+			args: []string{"git", "commit", "--trailer", "Co-Authored-By: Bob <bob@example.com>", "-m", "Don't panic"},
+			want: `git commit --trailer 'Co-Authored-By: Bob <bob@example.com>' -m 'Don'\''t panic'`,
+		},
+		{
+			name: "Empty argument",
+			args: []string{"git", "commit", "-m", ""},
+			want: "git commit -m ''",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatCommand(tt.args); got != tt.want {
+				t.Errorf("formatCommand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatEnv(t *testing.T) {
+	env := []string{"GIT_COCOMMIT_ACTIVE=1", "GIT_EDITOR=vim -f", "EMPTY="}
+	want := "GIT_COCOMMIT_ACTIVE=1 GIT_EDITOR='vim -f' EMPTY='' "
+	if got := formatEnv(env); got != want {
+		t.Errorf("formatEnv() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,355 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// optionArg describes whether a git commit option takes an argument
+type optionArg int
+
+const (
+	argNone optionArg = iota
+	argRequired
+	// argOptional options only take an argument attached to them (-S<keyid>, --gpg-sign=<keyid>)
+	argOptional
+)
+
+// commitOption is an entry of git commit's option table
+type commitOption struct {
+	short byte
+	long  string
+	arg   optionArg
+}
+
+// commitOptions mirrors the options accepted by git commit
+var commitOptions = []commitOption{
+	// Message sources
+	{'m', "message", argRequired},
+	{'F', "file", argRequired},
+	{'C', "reuse-message", argRequired},
+	{'c', "reedit-message", argRequired},
+	{0, "fixup", argRequired},
+	{0, "squash", argRequired},
+	{'t', "template", argRequired},
+	{'e', "edit", argNone},
+	{0, "no-edit", argNone},
+	{0, "cleanup", argRequired},
+	{0, "trailer", argRequired},
+	{'s', "signoff", argNone},
+	{0, "no-signoff", argNone},
+	{0, "allow-empty-message", argNone},
+
+	// Authorship
+	{0, "author", argRequired},
+	{0, "date", argRequired},
+	{0, "reset-author", argNone},
+	{0, "amend", argNone},
+
+	// Contents
+	{'a', "all", argNone},
+	{'i', "include", argNone},
+	{'o', "only", argNone},
+	{'p', "patch", argNone},
+	{0, "interactive", argNone},
+	{0, "pathspec-from-file", argRequired},
+	{0, "pathspec-file-nul", argNone},
+	{0, "allow-empty", argNone},
+	{'u', "untracked-files", argOptional},
+
+	// Behavior
+	{'n', "no-verify", argNone},
+	{0, "verify", argNone},
+	{0, "no-post-rewrite", argNone},
+	{'S', "gpg-sign", argOptional},
+	{0, "no-gpg-sign", argNone},
+	{'q', "quiet", argNone},
+	{'v', "verbose", argNone},
+	{0, "dry-run", argNone},
+	{0, "short", argNone},
+	{0, "branch", argNone},
+	{0, "porcelain", argNone},
+	{0, "long", argNone},
+	{'z', "null", argNone},
+	{0, "status", argNone},
+	{0, "no-status", argNone},
+	{0, "ahead-behind", argNone},
+	{0, "no-ahead-behind", argNone},
+}
+
+// commitArgs is the result of parsing git commit arguments
+type commitArgs struct {
+	// messages holds the -m values in order
+	messages []string
+	// file is the -F value
+	file string
+	// reuse is the -C value
+	reuse string
+	// reedit is the -c value
+	reedit string
+	// fixup is set when --fixup or --squash is given; git generates the message itself
+	fixup bool
+	// edit is set by -e/--edit, cleared by --no-edit, and nil if neither was given
+	edit *bool
+	// cleanup is the --cleanup value
+	cleanup string
+
+	amend       bool
+	resetAuthor bool
+	hasAuthor   bool
+	hasDate     bool
+
+	// rest holds all other arguments in order
+	rest []string
+}
+
+// findShortOption looks up a short option, treating unknown ones as flags
+func findShortOption(c byte) commitOption {
+	for _, opt := range commitOptions {
+		if opt.short == c {
+			return opt
+		}
+	}
+	return commitOption{short: c, arg: argNone}
+}
+
+// findLongOption looks up a long option by its name or an unambiguous prefix of it, like git does
+func findLongOption(name string) (commitOption, bool) {
+	var matches []commitOption
+	for _, opt := range commitOptions {
+		if opt.long == name {
+			return opt, true
+		}
+		if strings.HasPrefix(opt.long, name) {
+			matches = append(matches, opt)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0], true
+	}
+	return commitOption{}, false
+}
+
+// parseCommitArgs parses git commit arguments
+func parseCommitArgs(args []string) (*commitArgs, error) {
+	parsed := &commitArgs{}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch {
+		case arg == "--":
+			// Everything after "--" is a pathspec
+			parsed.rest = append(parsed.rest, args[i:]...)
+			return parsed, parsed.validate()
+
+		case strings.HasPrefix(arg, "--"):
+			name, value, hasValue := strings.Cut(arg[2:], "=")
+			opt, ok := findLongOption(name)
+			if !ok {
+				// Unknown options are passed to git as is
+				parsed.rest = append(parsed.rest, arg)
+				continue
+			}
+			if opt.arg == argRequired && !hasValue {
+				if i+1 >= len(args) {
+					return nil, fmt.Errorf("option `%s' requires a value", opt.long)
+				}
+				i++
+				value = args[i]
+			}
+			parsed.apply(opt, value, "--"+opt.long)
+
+		case strings.HasPrefix(arg, "-") && arg != "-":
+			// A cluster of short options, such as -am "message" or -mmessage
+			for j := 1; j < len(arg); j++ {
+				opt := findShortOption(arg[j])
+				flag := "-" + string(arg[j])
+				if opt.arg == argNone {
+					parsed.apply(opt, "", flag)
+					continue
+				}
+
+				value := arg[j+1:]
+				if opt.arg == argRequired && value == "" {
+					if i+1 >= len(args) {
+						return nil, fmt.Errorf("switch `%c' requires a value", arg[j])
+					}
+					i++
+					value = args[i]
+				}
+				parsed.apply(opt, value, flag)
+				break
+			}
+
+		default:
+			parsed.rest = append(parsed.rest, arg)
+		}
+	}
+
+	return parsed, parsed.validate()
+}
+
+// apply records an option, keeping options other than message sources in rest
+// flag is the spelling of the option to pass on to git.
+func (p *commitArgs) apply(opt commitOption, value, flag string) {
+	switch opt.long {
+	case "message":
+		p.messages = append(p.messages, value)
+		return
+	case "file":
+		p.file = value
+		return
+	case "reuse-message":
+		p.reuse = value
+		return
+	case "reedit-message":
+		p.reedit = value
+		return
+	case "fixup", "squash":
+		p.fixup = true
+	case "edit":
+		edit := true
+		p.edit = &edit
+	case "no-edit":
+		edit := false
+		p.edit = &edit
+	case "cleanup":
+		p.cleanup = value
+	case "amend":
+		p.amend = true
+	case "reset-author":
+		p.resetAuthor = true
+	case "author":
+		p.hasAuthor = true
+	case "date":
+		p.hasDate = true
+	}
+
+	switch {
+	case opt.arg == argRequired && strings.HasPrefix(flag, "--"):
+		p.rest = append(p.rest, flag+"="+value)
+	case opt.arg == argRequired:
+		p.rest = append(p.rest, flag, value)
+	case opt.arg == argOptional && value != "" && strings.HasPrefix(flag, "--"):
+		p.rest = append(p.rest, flag+"="+value)
+	default:
+		p.rest = append(p.rest, flag+value)
+	}
+}
+
+// validate rejects combinations of message sources that git commit rejects
+func (p *commitArgs) validate() error {
+	sources := 0
+	for _, set := range []bool{p.file != "", p.reuse != "", p.reedit != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return errors.New("only one of -c/-C/-F can be used")
+	}
+	if sources > 0 && len(p.messages) > 0 {
+		return errors.New("options '-m' and '-c/-C/-F' cannot be used together")
+	}
+	if sources > 0 && p.fixup {
+		return errors.New("options '--fixup/--squash' and '-c/-C/-F' cannot be used together")
+	}
+	return nil
+}
+
+// hasMessage reports whether the message is given on the command line (-m, -F, -C or -c)
+func (p *commitArgs) hasMessage() bool {
+	return len(p.messages) > 0 || p.file != "" || p.reuse != "" || p.reedit != ""
+}
+
+// editing reports whether git opens the editor on the message
+func (p *commitArgs) editing() bool {
+	if p.edit != nil {
+		return *p.edit
+	}
+	return p.reedit != "" || (!p.hasMessage() && !p.fixup)
+}
+
+// message builds the commit message from the message source
+// Multiple -m values become separate paragraphs, as in git.
+func (p *commitArgs) message() (string, error) {
+	switch {
+	case len(p.messages) > 0:
+		return strings.Join(p.messages, "\n\n"), nil
+	case p.file == "-":
+		content, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read commit message from standard input: %w", err)
+		}
+		return string(content), nil
+	case p.file != "":
+		content, err := os.ReadFile(p.file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read commit message file: %w", err)
+		}
+		return string(content), nil
+	case p.reuse != "":
+		return getCommitMessage(p.reuse)
+	case p.reedit != "":
+		return getCommitMessage(p.reedit)
+	default:
+		return "", nil
+	}
+}
+
+// command builds a git commit command line from the remaining arguments
+// extra options are inserted before any "--" so they are not taken as pathspecs.
+func (p *commitArgs) command(extra ...string) []string {
+	args := []string{"commit"}
+	for i, arg := range p.rest {
+		if arg == "--" {
+			args = append(args, extra...)
+			return append(args, p.rest[i:]...)
+		}
+		args = append(args, arg)
+	}
+	return append(args, extra...)
+}
+
+// authorshipArgs returns --author and --date options that reuse the authorship of
+// the -C/-c commit, since the message is passed to git with -F instead
+func (p *commitArgs) authorshipArgs() ([]string, error) {
+	commit := p.reuse
+	if commit == "" {
+		commit = p.reedit
+	}
+	if commit == "" || p.resetAuthor {
+		return nil, nil
+	}
+
+	cmd := exec.Command("git", "log", "-1", "--format=%an <%ae>%x00%aI", commit)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authorship of '%s': %w", commit, err)
+	}
+	author, date, _ := strings.Cut(strings.TrimSpace(string(out)), "\x00")
+
+	var args []string
+	if !p.hasAuthor {
+		args = append(args, "--author="+author)
+	}
+	if !p.hasDate {
+		args = append(args, "--date="+date)
+	}
+	return args, nil
+}
+
+// getCommitMessage gets the full message of a commit
+func getCommitMessage(commit string) (string, error) {
+	cmd := exec.Command("git", "log", "-1", "--format=%B", commit)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get message of '%s': %w", commit, err)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
@@ -0,0 +1,194 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCommitArgs(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		wantMessages []string
+		wantFile     string
+		wantReuse    string
+		wantReedit   string
+		wantFixup    bool
+		wantRest     []string
+		wantErr      bool
+	}{
+		{
+			name:         "Separate message",
+			args:         []string{"-m", "Fix bug"},
+			wantMessages: []string{"Fix bug"},
+		},
+		{
+			name:         "Attached message",
+			args:         []string{"-mFix bug"},
+			wantMessages: []string{"Fix bug"},
+		},
+		{
+			name:         "Message in a short option cluster",
+			args:         []string{"-am", "Fix bug"},
+			wantMessages: []string{"Fix bug"},
+			wantRest:     []string{"-a"},
+		},
+		{
+			name:         "Long option with value",
+			args:         []string{"--message=Fix bug"},
+			wantMessages: []string{"Fix bug"},
+		},
+		{
+			name:         "Long option prefix",
+			args:         []string{"--mess", "Fix bug"},
+			wantMessages: []string{"Fix bug"},
+		},
+		{
+			name:         "Multiple messages",
+			args:         []string{"-m", "Fix bug", "-m", "Details"},
+			wantMessages: []string{"Fix bug", "Details"},
+		},
+		{
+			name:     "Message file",
+			args:     []string{"-F", "msg.txt", "--no-verify"},
+			wantFile: "msg.txt",
+			wantRest: []string{"--no-verify"},
+		},
+		{
+			name:      "Reuse message",
+			args:      []string{"-C", "HEAD"},
+			wantReuse: "HEAD",
+		},
+		{
+			name:       "Reedit message",
+			args:       []string{"--reedit-message=HEAD~1"},
+			wantReedit: "HEAD~1",
+		},
+		{
+			name:         "Fixup keeps its arguments",
+			args:         []string{"--fixup", "abc123", "-m", "Extra"},
+			wantFixup:    true,
+			wantMessages: []string{"Extra"},
+			wantRest:     []string{"--fixup=abc123"},
+		},
+		{
+			name:     "Options with values are not messages",
+			args:     []string{"--author", "A <a@example.com>", "-S", "-uno"},
+			wantRest: []string{"--author=A <a@example.com>", "-S", "-uno"},
+		},
+		{
+			name:     "Pathspecs after double dash",
+			args:     []string{"-a", "--", "-m"},
+			wantRest: []string{"-a", "--", "-m"},
+		},
+		{
+			name:     "Unknown options are passed through",
+			args:     []string{"--unknown-option", "file.go"},
+			wantRest: []string{"--unknown-option", "file.go"},
+		},
+		{
+			name:    "Missing message",
+			args:    []string{"-m"},
+			wantErr: true,
+		},
+		{
+			name:    "Missing long option value",
+			args:    []string{"--file"},
+			wantErr: true,
+		},
+		{
+			name:    "Message with file",
+			args:    []string{"-m", "Fix bug", "-F", "msg.txt"},
+			wantErr: true,
+		},
+		{
+			name:    "Reuse with file",
+			args:    []string{"-C", "HEAD", "-F", "msg.txt"},
+			wantErr: true,
+		},
+		{
+			name:    "Fixup with reuse",
+			args:    []string{"--squash=HEAD", "-C", "HEAD"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCommitArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCommitArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got.messages, tt.wantMessages) {
+				t.Errorf("parseCommitArgs() messages = %q, want %q", got.messages, tt.wantMessages)
+			}
+			if got.file != tt.wantFile {
+				t.Errorf("parseCommitArgs() file = %q, want %q", got.file, tt.wantFile)
+			}
+			if got.reuse != tt.wantReuse {
+				t.Errorf("parseCommitArgs() reuse = %q, want %q", got.reuse, tt.wantReuse)
+			}
+			if got.reedit != tt.wantReedit {
+				t.Errorf("parseCommitArgs() reedit = %q, want %q", got.reedit, tt.wantReedit)
+			}
+			if got.fixup != tt.wantFixup {
+				t.Errorf("parseCommitArgs() fixup = %v, want %v", got.fixup, tt.wantFixup)
+			}
+			if !reflect.DeepEqual(got.rest, tt.wantRest) {
+				t.Errorf("parseCommitArgs() rest = %q, want %q", got.rest, tt.wantRest)
+			}
+		})
+	}
+}
+
+func TestCommitArgsMessage(t *testing.T) {
+	parsed, err := parseCommitArgs([]string{"-m", "Fix bug", "-m", "Details"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parsed.message()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Fix bug\n\nDetails"; got != want {
+		t.Errorf("message() = %q, want %q", got, want)
+	}
+}
+
+func TestCommitArgsCommand(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		extra []string
+		want  []string
+	}{
+		{
+			name:  "Without pathspecs",
+			args:  []string{"-a", "-m", "Fix bug"},
+			extra: []string{"-F", "msg"},
+			want:  []string{"commit", "-a", "-F", "msg"},
+		},
+		{
+			name:  "Extra options go before double dash",
+			args:  []string{"-m", "Fix bug", "--", "file.go"},
+			extra: []string{"-F", "msg"},
+			want:  []string{"commit", "-F", "msg", "--", "file.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := parseCommitArgs(tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := parsed.command(tt.extra...)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("command() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// editorEnv holds the editor git would have used
+	editorEnv = "GIT_COCOMMIT_EDITOR"
+	// editorTrailersEnv holds the trailers to add, one per line
+	editorTrailersEnv = "GIT_COCOMMIT_TRAILERS"
+	// editorCleanupEnv holds the cleanup mode git applies to the edited message
+	editorCleanupEnv = "GIT_COCOMMIT_CLEANUP"
+	// commitMessageFile is the name of the file git commit edits the message in
+	commitMessageFile = "COMMIT_EDITMSG"
+)
+
+// commitWithEditor executes git commit and lets git prepare the message and open the editor
+// git cocommit stands in as the editor so the co-authors are added to the message git prepared,
+// keeping commit.template, -v diffs, the status summary and cleanup modes as in git commit.
+// With dryRun, the trailers and the command with its environment are printed instead.
+func commitWithEditor(args []string, trailers []trailer, cleanup cleanupMode, dryRun bool) error {
+	out, err := exec.Command("git", "var", "GIT_EDITOR").Output()
+	if err != nil {
+		return fmt.Errorf("failed to get editor: %w", err)
+	}
+	editor := strings.TrimSpace(string(out))
+
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find git-cocommit executable: %w", err)
+	}
+
+	env := []string{
+		"GIT_EDITOR=" + shellQuote(self) + " editor",
+		editorEnv + "=" + editor,
+		editorTrailersEnv + "=" + formatTrailers(trailers),
+		editorCleanupEnv + "=" + string(cleanup),
+	}
+	if dryRun {
+		printDryRun("Trailers added to the message in the editor:", formatTrailers(trailers), args, env...)
+		return nil
+	}
+	return runGit(args, env...)
+}
+
+// Editor adds the co-authors to the commit message file and opens the user's editor on it
+// git cocommit sets it as GIT_EDITOR; it is not meant to be run directly.
+// Other files, such as the hunks edited in git commit -p, are opened unchanged.
+// Usage: git cocommit editor <file>
+func Editor(args []string) error {
+	editor := os.Getenv(editorEnv)
+	if len(args) != 1 || editor == "" {
+		return errors.New("git cocommit editor is used internally by git cocommit")
+	}
+	path := args[0]
+	if filepath.Base(path) != commitMessageFile {
+		return runEditor(editor, path)
+	}
+
+	var trailers []trailer
+	for _, line := range strings.Split(os.Getenv(editorTrailersEnv), "\n") {
+		if t, ok := parseTrailer(line); ok {
+			trailers = append(trailers, t)
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read commit message: %w", err)
+	}
+	commentChar := getPreparedCommentChar(string(content))
+	if err := os.WriteFile(path, []byte(insertTrailers(string(content), trailers, commentChar)), 0644); err != nil {
+		return fmt.Errorf("failed to update commit message file: %w", err)
+	}
+
+	if err := runEditor(editor, path); err != nil {
+		return err
+	}
+
+	// Leaving only the added trailers should still abort the commit like an empty message
+	content, err = os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read commit message: %w", err)
+	}
+	cleanup, err := parseCleanupMode(os.Getenv(editorCleanupEnv))
+	if err != nil {
+		return err
+	}
+	message := cleanupMessage(cutScissors(string(content), commentChar), cleanup, commentChar, true)
+	if hasOnlyTrailers(message, trailers) {
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			return fmt.Errorf("failed to update commit message file: %w", err)
+		}
+	}
+	return nil
+}
+
+// runEditor opens the user's editor on path
+func runEditor(editor, path string) error {
+	// The editor setting is a shell command, as in git
+	cmd := exec.Command("sh", "-c", editor+` "$@"`, editor, path)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to open editor: %w", err)
+	}
+	return nil
+}
+
+// hasOnlyTrailers reports whether the cleaned up message contains nothing but the added trailers
+func hasOnlyTrailers(message string, trailers []trailer) bool {
+	added := map[string]bool{}
+	for _, t := range trailers {
+		added[t.String()] = true
+	}
+
+	for _, line := range strings.Split(message, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !added[line] {
+			return false
+		}
+	}
+	return true
+}
+
+// shellQuote quotes s for use in a shell command
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,114 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHasOnlyTrailers(t *testing.T) {
+	trailers := []trailer{{key: coAuthoredByKey, value: "alice <alice@example.com>"}}
+
+	tests := []struct {
+		name    string
+		message string
+		want    bool
+	}{
+		{
+			name:    "Only co-authors",
+			message: "Co-Authored-By: alice <alice@example.com>\n",
+			want:    true,
+		},
+		{
+			name:    "Empty message",
+			message: "",
+			want:    true,
+		},
+		{
+			name:    "Message with co-authors",
+			message: "Fix bug\n\nCo-Authored-By: alice <alice@example.com>\n",
+			want:    false,
+		},
+		{
+			name:    "Other trailer",
+			message: "Co-Authored-By: bob <bob@example.com>\n",
+			want:    false,
+		},
+		{
+			name:    "Comments kept by the cleanup mode",
+			message: "Co-Authored-By: alice <alice@example.com>\n\n# Please enter the commit message\n",
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasOnlyTrailers(tt.message, trailers); got != tt.want {
+				t.Errorf("hasOnlyTrailers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"/usr/local/bin/git-cocommit", "'/usr/local/bin/git-cocommit'"},
+		{"/home/o'neil/bin/git-cocommit", `'/home/o'\''neil/bin/git-cocommit'`},
+	}
+
+	for _, tt := range tests {
+		if got := shellQuote(tt.input); got != tt.want {
+			t.Errorf("shellQuote(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestEditor(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    string
+	}{
+		{
+			name:    "Commit message",
+			file:    "COMMIT_EDITMSG",
+			content: "Fix bug\n",
+			want:    "Fix bug\n\nCo-Authored-By: alice <alice@example.com>\n",
+		},
+		{
+			name:    "Hunk edited in git commit -p",
+			file:    "addp-hunk-edit.diff",
+			content: "@@ -1 +1 @@\n-a\n+b\n",
+			want:    "@@ -1 +1 @@\n-a\n+b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestRepo(t)
+			t.Setenv(editorEnv, "true")
+			t.Setenv(editorTrailersEnv, "Co-Authored-By: alice <alice@example.com>")
+			t.Setenv(editorCleanupEnv, "strip")
+
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := Editor([]string{path}); err != nil {
+				t.Fatalf("Editor() error = %v", err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Editor() wrote %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
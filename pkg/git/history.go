@@ -0,0 +1,203 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net/mail"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// historyHalfLife is the age at which a commit counts half as much when ranking authors
+const historyHalfLife = 30 * 24 * time.Hour
+
+// historyCommit is a commit author and time read from the Git history
+type historyCommit struct {
+	author string
+	time   time.Time
+}
+
+// getHistoryLimits gets how far back and how many authors to offer from the Git history
+// --since and --max take precedence over the COCOMMIT_HISTORY_SINCE and COCOMMIT_HISTORY_MAX
+// environment variables and the cocommit.history.since and cocommit.history.max git configs.
+func getHistoryLimits(opts options) (string, int, error) {
+	since := opts.since
+	if since == "" {
+		since = getSetting("COCOMMIT_HISTORY_SINCE", "cocommit.history.since")
+	}
+
+	value := opts.max
+	if value == "" {
+		value = getSetting("COCOMMIT_HISTORY_MAX", "cocommit.history.max")
+	}
+	if value == "" {
+		return since, 0, nil
+	}
+	max, err := strconv.Atoi(value)
+	if err != nil || max < 0 {
+		return "", 0, fmt.Errorf("invalid maximum number of authors '%s'", value)
+	}
+	return since, max, nil
+}
+
+// identityKey returns the lowercased email address of a "Name <email>" identity, which identifies
+// the same person across spellings of their name, or the lowercased identity if it has no email address
+func identityKey(identity string) string {
+	if address, err := mail.ParseAddress(identity); err == nil {
+		return strings.ToLower(address.Address)
+	}
+	return strings.ToLower(identity)
+}
+
+// rankAuthors ranks commit authors and co-authors by recency-weighted frequency
+// Each commit scores 0.5^(age/historyHalfLife), so frequent and recent collaborators come first.
+// Ties are broken by the most recent commit. People are told apart by email address, and listed
+// with the first identity seen for them.
+func rankAuthors(commits []historyCommit, now time.Time) []string {
+	scores := map[string]float64{}
+	last := map[string]time.Time{}
+	identities := map[string]string{}
+	var keys []string
+
+	for _, c := range commits {
+		key := identityKey(c.author)
+		if _, ok := identities[key]; !ok {
+			identities[key] = c.author
+			keys = append(keys, key)
+		}
+		age := now.Sub(c.time)
+		if age < 0 {
+			age = 0
+		}
+		scores[key] += math.Pow(0.5, float64(age)/float64(historyHalfLife))
+		if c.time.After(last[key]) {
+			last[key] = c.time
+		}
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if scores[a] != scores[b] {
+			return scores[a] > scores[b]
+		}
+		return last[a].After(last[b])
+	})
+
+	var authors []string
+	for _, key := range keys {
+		authors = append(authors, identities[key])
+	}
+	return authors
+}
+
+// readHistory reads the authors and co-authors of the commits since the given date (all commits if empty)
+// Co-authors credited with Co-Authored-By trailers, or trailers with the configured key, count as
+// having made the commit, so people who pair but rarely commit themselves are found too.
+func readHistory(since string) ([]historyCommit, error) {
+	key, err := getTrailerKey()
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"log", "--format=%aN <%aE>%x00%at%x00%(trailers:key=" + coAuthoredByKey + ",key=" + key + ",valueonly,separator=%x01)"}
+	if since != "" {
+		args = append(args, "--since="+since)
+	}
+
+	cmd := exec.Command("git", args...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to get git authors: %w", err)
+	}
+
+	var commits []historyCommit
+	for _, line := range strings.Split(out.String(), "\n") {
+		commits = append(commits, parseHistoryLine(line)...)
+	}
+
+	// %aN and %aE apply .mailmap to authors, but trailers are left as written
+	var people []string
+	for _, c := range commits {
+		people = append(people, c.author)
+	}
+	canonical := canonicalizeIdentities(people)
+	for i := range commits {
+		commits[i].author = canonical[commits[i].author]
+	}
+	return commits, nil
+}
+
+// canonicalizeIdentities maps "Name <email>" identities to their canonical form according to .mailmap
+// Identities git check-mailmap cannot parse, or all of them if it fails, are mapped to themselves.
+func canonicalizeIdentities(identities []string) map[string]string {
+	canonical := map[string]string{}
+	var input []string
+	for _, identity := range identities {
+		if _, ok := canonical[identity]; ok {
+			continue
+		}
+		canonical[identity] = identity
+		if strings.Contains(identity, "<") && strings.HasSuffix(identity, ">") {
+			input = append(input, identity)
+		}
+	}
+	if len(input) == 0 {
+		return canonical
+	}
+
+	cmd := exec.Command("git", "check-mailmap", "--stdin")
+	cmd.Stdin = strings.NewReader(strings.Join(input, "\n") + "\n")
+	out, err := cmd.Output()
+	if err != nil {
+		return canonical
+	}
+
+	lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	if len(lines) != len(input) {
+		return canonical
+	}
+	for i, identity := range input {
+		canonical[identity] = lines[i]
+	}
+	return canonical
+}
+
+// currentUserMatcher returns a function reporting whether an identity belongs to the current user,
+// comparing email addresses both as configured and as mapped by .mailmap
+func currentUserMatcher(currentUser string) func(string) bool {
+	keys := map[string]bool{identityKey(currentUser): true}
+	keys[identityKey(canonicalizeIdentities([]string{currentUser})[currentUser])] = true
+	return func(identity string) bool {
+		return keys[identityKey(identity)]
+	}
+}
+
+// parseHistoryLine parses a "author\x00time\x00co-authors" line of readHistory's git log output
+// into a historyCommit for the author and each co-author.
+func parseHistoryLine(line string) []historyCommit {
+	fields := strings.SplitN(line, "\x00", 3)
+	if len(fields) < 2 {
+		return nil
+	}
+	seconds, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return nil
+	}
+	t := time.Unix(seconds, 0)
+
+	var commits []historyCommit
+	people := []string{fields[0]}
+	if len(fields) == 3 {
+		people = append(people, strings.Split(fields[2], "\x01")...)
+	}
+	for _, person := range people {
+		if person = strings.TrimSpace(person); person != "" {
+			commits = append(commits, historyCommit{author: person, time: t})
+		}
+	}
+	return commits
+}
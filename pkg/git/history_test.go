@@ -0,0 +1,175 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRankAuthors(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time {
+		return now.Add(-time.Duration(days) * 24 * time.Hour)
+	}
+
+	tests := []struct {
+		name    string
+		commits []historyCommit
+		want    []string
+	}{
+		{
+			name: "Frequent authors first",
+			commits: []historyCommit{
+				{"alice <a@example.com>", daysAgo(1)},
+				{"bob <b@example.com>", daysAgo(2)},
+				{"bob <b@example.com>", daysAgo(3)},
+			},
+			want: []string{"bob <b@example.com>", "alice <a@example.com>"},
+		},
+		{
+			name: "Recent commits outweigh old ones",
+			commits: []historyCommit{
+				{"alice <a@example.com>", daysAgo(1)},
+				{"bob <b@example.com>", daysAgo(365)},
+				{"bob <b@example.com>", daysAgo(366)},
+				{"bob <b@example.com>", daysAgo(367)},
+			},
+			want: []string{"alice <a@example.com>", "bob <b@example.com>"},
+		},
+		{
+			name: "Ties go to the most recent author",
+			commits: []historyCommit{
+				{"alice <a@example.com>", daysAgo(5)},
+				{"bob <b@example.com>", daysAgo(5)},
+				{"carol <c@example.com>", daysAgo(5)},
+			},
+			want: []string{"alice <a@example.com>", "bob <b@example.com>", "carol <c@example.com>"},
+		},
+		{
+			name: "Same email with different names",
+			commits: []historyCommit{
+				{"Alice Smith <alice@example.com>", daysAgo(1)},
+				{"bob <b@example.com>", daysAgo(1)},
+				{"alice <Alice@Example.com>", daysAgo(2)},
+			},
+			want: []string{"Alice Smith <alice@example.com>", "bob <b@example.com>"},
+		},
+		{
+			name: "Same email with a quoted name",
+			commits: []historyCommit{
+				{`"Doe, John" <john@example.com>`, daysAgo(1)},
+				{"John Doe <John@Example.com>", daysAgo(2)},
+			},
+			want: []string{`"Doe, John" <john@example.com>`},
+		},
+		{
+			name:    "No commits",
+			commits: nil,
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rankAuthors(tt.commits, now)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rankAuthors() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHistoryLine(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name string
+		line string
+		want []historyCommit
+	}{
+		{
+			name: "Author only",
+			line: "alice <a@example.com>\x001700000000\x00",
+			want: []historyCommit{{"alice <a@example.com>", at}},
+		},
+		{
+			name: "Author and co-authors",
+			line: "alice <a@example.com>\x001700000000\x00bob <b@example.com>\x01carol <c@example.com>",
+			want: []historyCommit{
+				{"alice <a@example.com>", at},
+				{"bob <b@example.com>", at},
+				{"carol <c@example.com>", at},
+			},
+		},
+		{
+			name: "Empty line",
+			line: "",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseHistoryLine(tt.line)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseHistoryLine() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetHistoryLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      options
+		envSince  string
+		envMax    string
+		wantSince string
+		wantMax   int
+		wantErr   bool
+	}{
+		{
+			name:      "Flags",
+			opts:      options{since: "2.weeks", max: "5"},
+			wantSince: "2.weeks",
+			wantMax:   5,
+		},
+		{
+			name:      "Environment variables",
+			envSince:  "1.month",
+			envMax:    "20",
+			wantSince: "1.month",
+			wantMax:   20,
+		},
+		{
+			name:      "Flags take precedence",
+			opts:      options{max: "3"},
+			envMax:    "20",
+			wantSince: "",
+			wantMax:   3,
+		},
+		{
+			name:    "Invalid maximum",
+			opts:    options{max: "many"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("COCOMMIT_HISTORY_SINCE", tt.envSince)
+			t.Setenv("COCOMMIT_HISTORY_MAX", tt.envMax)
+
+			gotSince, gotMax, err := getHistoryLimits(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getHistoryLimits() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if gotSince != tt.wantSince || gotMax != tt.wantMax {
+				t.Errorf("getHistoryLimits() = %q, %d, want %q, %d", gotSince, gotMax, tt.wantSince, tt.wantMax)
+			}
+		})
+	}
+}
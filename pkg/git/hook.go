@@ -0,0 +1,261 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// hookName is the git hook used to inject co-authors
+	hookName = "prepare-commit-msg"
+	// hookMarker identifies a hook installed by git cocommit
+	hookMarker = "# Installed by git-cocommit"
+	// chainedHookSuffix is appended to the name of an existing hook that is kept and chained
+	chainedHookSuffix = ".cocommit-orig"
+	// hookSkipEnv is set when git cocommit runs git commit itself, so the hook does not add co-authors twice
+	hookSkipEnv = "GIT_COCOMMIT_ACTIVE"
+)
+
+// hookScript is the prepare-commit-msg hook installed by git cocommit
+// It runs the hook it replaced first, then adds the active co-authors.
+const hookScript = `#!/bin/sh
+` + hookMarker + `
+# The previous ` + hookName + ` hook, if any, is kept as ` + hookName + chainedHookSuffix + `
+orig="$(dirname "$0")/` + hookName + chainedHookSuffix + `"
+if [ -x "$orig" ]; then
+	"$orig" "$@" || exit $?
+fi
+exec git cocommit hook run "$@"
+`
+
+// getHooksDir gets the hooks directory, respecting core.hooksPath
+func getHooksDir() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--git-path", "hooks")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.New("not inside a git repository")
+	}
+	return filepath.Abs(strings.TrimSpace(string(out)))
+}
+
+// isCocommitHook reports whether the hook at path was installed by git cocommit
+func isCocommitHook(path string) bool {
+	content, err := os.ReadFile(path)
+	return err == nil && strings.Contains(string(content), hookMarker)
+}
+
+// Hook manages the prepare-commit-msg hook
+// Usage: git cocommit hook install|uninstall
+//
+//	git cocommit hook run <message-file> [<source> [<commit>]]  (called by the hook)
+func Hook(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: git cocommit hook install|uninstall")
+	}
+
+	switch args[0] {
+	case "install":
+		return installHook()
+	case "uninstall":
+		return uninstallHook()
+	case "run":
+		return runHook(args[1:])
+	default:
+		return fmt.Errorf("unknown hook subcommand '%s'", args[0])
+	}
+}
+
+// installHook installs the prepare-commit-msg hook, keeping any existing hook as a chained hook
+func installHook() error {
+	hooksDir, err := getHooksDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	path := filepath.Join(hooksDir, hookName)
+	if isCocommitHook(path) {
+		fmt.Printf("Hook already installed in %s\n", path)
+		return nil
+	}
+
+	// Keep the existing hook so it still runs before ours
+	if _, err := os.Stat(path); err == nil {
+		chained := path + chainedHookSuffix
+		if _, err := os.Stat(chained); err == nil {
+			return fmt.Errorf("cannot keep existing hook: %s already exists", chained)
+		}
+		if err := os.Rename(path, chained); err != nil {
+			return fmt.Errorf("failed to move existing hook: %w", err)
+		}
+		fmt.Printf("Existing hook moved to %s and chained\n", chained)
+	}
+
+	if err := os.WriteFile(path, []byte(hookScript), 0755); err != nil {
+		return fmt.Errorf("failed to write hook: %w", err)
+	}
+
+	fmt.Printf("Hook installed in %s\n", path)
+	return nil
+}
+
+// uninstallHook removes the prepare-commit-msg hook and restores the hook it replaced
+func uninstallHook() error {
+	hooksDir, err := getHooksDir()
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(hooksDir, hookName)
+	if !isCocommitHook(path) {
+		return fmt.Errorf("no git cocommit hook installed in %s", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove hook: %w", err)
+	}
+
+	chained := path + chainedHookSuffix
+	if _, err := os.Stat(chained); err == nil {
+		if err := os.Rename(chained, path); err != nil {
+			return fmt.Errorf("failed to restore previous hook: %w", err)
+		}
+		fmt.Printf("Previous hook restored in %s\n", path)
+	}
+
+	fmt.Println("Hook uninstalled")
+	return nil
+}
+
+// runHook adds the active co-authors to the commit message file
+// Failures only print a warning, since a missing trailer should never block a commit.
+func runHook(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: git cocommit hook run <message-file> [<source> [<commit>]]")
+	}
+	if os.Getenv(hookSkipEnv) != "" {
+		return nil
+	}
+
+	// Merge and squash messages are generated by git
+	if len(args) > 1 && (args[1] == "merge" || args[1] == "squash") {
+		return nil
+	}
+
+	// Commits replayed by rebase, cherry-pick and revert were made without the current co-authors
+	if isReplayingCommits() {
+		return nil
+	}
+
+	if err := injectActiveCoAuthors(args[0]); err != nil {
+		fmt.Fprintf(os.Stderr, "git-cocommit: co-authors not added: %v\n", err)
+	}
+	return nil
+}
+
+// replayStatePaths are the files and directories git keeps while a rebase, cherry-pick or revert is in progress
+var replayStatePaths = []string{"rebase-merge", "rebase-apply", "CHERRY_PICK_HEAD", "REVERT_HEAD"}
+
+// isReplayingCommits reports whether a rebase, cherry-pick or revert is in progress
+func isReplayingCommits() bool {
+	args := []string{"rev-parse"}
+	for _, path := range replayStatePaths {
+		args = append(args, "--git-path", path)
+	}
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return false
+	}
+	for _, path := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// getActiveCoAuthors gets the co-authors to add without prompting
+// Returns nil if no co-authors are active.
+func getActiveCoAuthors() ([]string, error) {
+	usernames := splitCoAuthors(os.Getenv("GIT_COAUTHORS"))
+	if len(usernames) > 0 {
+		return resolveCoAuthors(usernames, options{})
+	}
+	s, err := loadSession()
+	if err != nil || s == nil {
+		return nil, err
+	}
+	return s.identities(options{})
+}
+
+// injectActiveCoAuthors adds the active co-authors to the commit message file at path
+func injectActiveCoAuthors(path string) error {
+	coAuthors, err := getActiveCoAuthors()
+	if err != nil || len(coAuthors) == 0 {
+		return err
+	}
+	key, err := getTrailerKey()
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read commit message: %w", err)
+	}
+
+	content = []byte(addHookTrailers(string(content), coAuthorTrailers(key, coAuthors), getPreparedCommentChar(string(content))))
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to update commit message file: %w", err)
+	}
+	return nil
+}
+
+// addHookTrailers adds trailers to a commit message file prepared by git, unless the message is empty
+// git aborts a commit whose message is left empty, but trailers other than Signed-off-by count as
+// content, so adding them to an empty message would make the commit anyway.
+func addHookTrailers(content string, trailers []trailer, commentChar string) string {
+	if strings.TrimSpace(cleanupMessage(cutScissors(content, commentChar), cleanupStrip, commentChar, true)) == "" {
+		return content
+	}
+	return insertTrailers(content, trailers, commentChar)
+}
+
+// insertTrailers adds trailers to a commit message file prepared by git
+// The trailers go after the message, before git's comment block and any scissors line.
+func insertTrailers(content string, trailers []trailer, commentChar string) string {
+	lines := strings.Split(content, "\n")
+
+	// A scissors line cuts off everything below it, even non-comment lines such as a diff
+	end := len(lines)
+	scissors := scissorsLine(commentChar)
+	for i, line := range lines {
+		if line == scissors {
+			end = i
+			break
+		}
+	}
+
+	// Find where the trailing comment block starts
+	for end > 0 {
+		line := lines[end-1]
+		if strings.TrimSpace(line) != "" && !strings.HasPrefix(line, commentChar) {
+			break
+		}
+		end--
+	}
+
+	message := strings.TrimRight(strings.Join(lines[:end], "\n"), "\n")
+	rest := strings.TrimLeft(strings.Join(lines[end:], "\n"), "\n")
+
+	result := mergeTrailers(message, trailers) + "\n"
+	if rest != "" {
+		result += "\n" + rest
+	}
+	return result
+}
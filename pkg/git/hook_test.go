@@ -0,0 +1,128 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInsertTrailers(t *testing.T) {
+	trailers := []trailer{{key: coAuthoredByKey, value: "alice <alice@example.com>"}}
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "Message only",
+			content: "Commit message\n",
+			want:    "Commit message\n\nCo-Authored-By: alice <alice@example.com>\n",
+		},
+		{
+			name:    "Message with comment block",
+			content: "Commit message\n\n# Please enter the commit message\n#\n# On branch main\n",
+			want:    "Commit message\n\nCo-Authored-By: alice <alice@example.com>\n\n# Please enter the commit message\n#\n# On branch main\n",
+		},
+		{
+			name:    "Empty message in the editor wrapper",
+			content: "\n# Please enter the commit message\n",
+			want:    "\n\nCo-Authored-By: alice <alice@example.com>\n\n# Please enter the commit message\n",
+		},
+		{
+			name:    "Scissors line with diff",
+			content: "Commit message\n\n# Comment\n# ------------------------ >8 ------------------------\ndiff --git a/a b/a\n+added\n",
+			want:    "Commit message\n\nCo-Authored-By: alice <alice@example.com>\n\n# Comment\n# ------------------------ >8 ------------------------\ndiff --git a/a b/a\n+added\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := insertTrailers(tt.content, trailers, "#")
+			if got != tt.want {
+				t.Errorf("insertTrailers() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddHookTrailers(t *testing.T) {
+	trailers := []trailer{{key: coAuthoredByKey, value: "alice <alice@example.com>"}}
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "Message",
+			content: "Commit message\n\n# Please enter the commit message\n",
+			want:    "Commit message\n\nCo-Authored-By: alice <alice@example.com>\n\n# Please enter the commit message\n",
+		},
+		{
+			name:    "Empty message",
+			content: "\n# Please enter the commit message\n#\n# On branch main\n",
+			want:    "\n# Please enter the commit message\n#\n# On branch main\n",
+		},
+		{
+			name:    "Empty message with diff below the scissors line",
+			content: "\n# Comment\n# ------------------------ >8 ------------------------\ndiff --git a/a b/a\n+added\n",
+			want:    "\n# Comment\n# ------------------------ >8 ------------------------\ndiff --git a/a b/a\n+added\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := addHookTrailers(tt.content, trailers, "#")
+			if got != tt.want {
+				t.Errorf("addHookTrailers() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsCocommitHook(t *testing.T) {
+	dir := t.TempDir()
+
+	ours := filepath.Join(dir, "ours")
+	if err := os.WriteFile(ours, []byte(hookScript), 0755); err != nil {
+		t.Fatal(err)
+	}
+	other := filepath.Join(dir, "other")
+	if err := os.WriteFile(other, []byte("#!/bin/sh\nexit 0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if !isCocommitHook(ours) {
+		t.Errorf("Expected installed hook to be recognized")
+	}
+	if isCocommitHook(other) {
+		t.Errorf("Expected other hook not to be recognized")
+	}
+	if isCocommitHook(filepath.Join(dir, "missing")) {
+		t.Errorf("Expected missing hook not to be recognized")
+	}
+}
+
+func TestIsReplayingCommits(t *testing.T) {
+	newTestRepo(t)
+	testCommit(t, "a.txt", "Base")
+
+	if isReplayingCommits() {
+		t.Fatal("isReplayingCommits() = true without a replay in progress")
+	}
+
+	for _, path := range replayStatePaths {
+		t.Run(path, func(t *testing.T) {
+			gitPath := testGit(t, "rev-parse", "--git-path", path)
+			if err := os.Mkdir(gitPath, 0755); err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(gitPath)
+
+			if !isReplayingCommits() {
+				t.Errorf("isReplayingCommits() = false with %s present", path)
+			}
+		})
+	}
+}
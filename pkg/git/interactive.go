@@ -0,0 +1,155 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/MITSUBOSHI/cocommit/pkg/picker"
+)
+
+// promptCoAuthors lets the user build the list of co-authors interactively
+// Usernames, aliases and teams can be typed and are resolved right away, and people can be picked
+// from the Git history, the registered aliases and the owners of the staged changes. The pending
+// co-authors are shown after each step, can be removed, and are used once confirmed.
+func promptCoAuthors(opts options) ([]string, error) {
+	reader := bufio.NewReader(os.Stdin)
+	var pending []string
+
+	for {
+		printPending(pending)
+		fmt.Println("Type a GitHub username, alias or team (comma-separated for several),")
+		fmt.Println("'p' to pick from Git history and aliases, 'o' to pick owners of the staged changes,")
+		fmt.Print("'-<number>' to remove, or press Enter to confirm: ")
+
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input: %w", err)
+		}
+		input = strings.TrimSpace(input)
+
+		var added []string
+		switch {
+		case input == "":
+			if len(pending) == 0 {
+				return nil, errors.New("at least one co-author is required")
+			}
+			return pending, nil
+		case input == "p":
+			added, err = pickCandidates(opts)
+		case input == "o":
+			added, err = selectStagedOwners()
+		case strings.HasPrefix(input, "-"):
+			pending, err = removePending(pending, input[1:])
+		default:
+			added, err = resolveCoAuthors(splitCoAuthors(input), opts)
+		}
+
+		if errors.Is(err, picker.ErrCancelled) {
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			continue
+		}
+		pending = addPending(pending, added)
+	}
+}
+
+// printPending shows the co-authors added so far
+func printPending(pending []string) {
+	fmt.Println()
+	if len(pending) == 0 {
+		fmt.Println("Co-authors: (none yet)")
+		return
+	}
+	fmt.Println("Co-authors:")
+	for i, coAuthor := range pending {
+		fmt.Printf("  %d. %s\n", i+1, coAuthor)
+	}
+}
+
+// addPending adds co-authors to the pending list, skipping people already on it
+func addPending(pending, added []string) []string {
+	for _, coAuthor := range added {
+		duplicate := false
+		for _, existing := range pending {
+			if identityKey(existing) == identityKey(coAuthor) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			pending = append(pending, coAuthor)
+		}
+	}
+	return pending
+}
+
+// removePending removes the co-author with the given 1-based number from the pending list
+func removePending(pending []string, number string) ([]string, error) {
+	n, err := strconv.Atoi(number)
+	if err != nil || n < 1 || n > len(pending) {
+		return pending, fmt.Errorf("invalid number: %s", number)
+	}
+	return append(pending[:n-1:n-1], pending[n:]...), nil
+}
+
+// pickCandidates lets the user pick co-authors among the registered aliases and the Git history
+func pickCandidates(opts options) ([]string, error) {
+	registry, err := loadAuthorRegistry()
+	if err != nil {
+		return nil, err
+	}
+	history, err := getGitAuthors(opts)
+	if err != nil {
+		return nil, err
+	}
+	currentUser, err := getCurrentGitUser()
+	if err != nil {
+		return nil, err
+	}
+	isCurrentUser := currentUserMatcher(currentUser)
+
+	// Aliases first, labelled with their handle, then history authors not registered as an alias
+	var items []string
+	identities := map[string]string{}
+	seen := map[string]bool{}
+	for _, handle := range registry.handles() {
+		identity, _ := registry.lookup(handle)
+		if isCurrentUser(identity) {
+			continue
+		}
+		item := fmt.Sprintf("%s [%s]", identity, handle)
+		items = append(items, item)
+		identities[item] = identity
+		seen[identityKey(identity)] = true
+	}
+	for _, author := range history {
+		if !seen[identityKey(author)] {
+			items = append(items, author)
+			identities[author] = author
+			seen[identityKey(author)] = true
+		}
+	}
+
+	if len(items) == 0 {
+		return nil, errors.New("no author information found in Git history or aliases")
+	}
+
+	selected, err := selectItems(items, "Select co-authors", "Available co-authors from aliases and Git history:")
+	if err != nil {
+		return nil, err
+	}
+
+	var coAuthors []string
+	for _, item := range selected {
+		if identity, ok := identities[item]; ok {
+			coAuthors = append(coAuthors, identity)
+		}
+	}
+	return coAuthors, nil
+}
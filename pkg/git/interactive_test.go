@@ -0,0 +1,89 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAddPending(t *testing.T) {
+	tests := []struct {
+		name    string
+		pending []string
+		added   []string
+		want    []string
+	}{
+		{
+			name:    "Add to empty list",
+			pending: nil,
+			added:   []string{"alice <alice@example.com>"},
+			want:    []string{"alice <alice@example.com>"},
+		},
+		{
+			name:    "Keep order",
+			pending: []string{"alice <alice@example.com>"},
+			added:   []string{"bob <bob@example.com>", "carol <carol@example.com>"},
+			want:    []string{"alice <alice@example.com>", "bob <bob@example.com>", "carol <carol@example.com>"},
+		},
+		{
+			name:    "Skip people already added",
+			pending: []string{"Alice Smith <alice@example.com>"},
+			added:   []string{"alice <Alice@Example.com>", "bob <bob@example.com>", "bob <bob@example.com>"},
+			want:    []string{"Alice Smith <alice@example.com>", "bob <bob@example.com>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := addPending(tt.pending, tt.added)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("addPending() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemovePending(t *testing.T) {
+	pending := []string{"alice <alice@example.com>", "bob <bob@example.com>", "carol <carol@example.com>"}
+
+	tests := []struct {
+		name    string
+		number  string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "Remove middle",
+			number: "2",
+			want:   []string{"alice <alice@example.com>", "carol <carol@example.com>"},
+		},
+		{
+			name:   "Remove last",
+			number: "3",
+			want:   []string{"alice <alice@example.com>", "bob <bob@example.com>"},
+		},
+		{
+			name:    "Out of range",
+			number:  "4",
+			want:    pending,
+			wantErr: true,
+		},
+		{
+			name:    "Not a number",
+			number:  "x",
+			want:    pending,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := removePending(pending, tt.number)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("removePending() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("removePending() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package git
+
+import (
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/MITSUBOSHI/cocommit/pkg/github"
+)
+
+// options holds git-cocommit specific flags, which are not passed on to git commit
+type options struct {
+	// refresh bypasses the GitHub user cache
+	refresh bool
+	// since limits the Git history authors are offered from (--since)
+	since string
+	// max limits the number of authors offered from the Git history (--max)
+	max string
+	// with lists the co-authors given on the command line (--with, -w)
+	with []string
+	// noPrompt fails instead of asking the user for input (--no-prompt)
+	noPrompt bool
+	// dryRun shows the commit message and git command instead of committing (--dry-run)
+	dryRun bool
+	// printTrailers only prints the co-author trailers (--print-trailers)
+	printTrailers bool
+	// trailers holds the --trailer values, which may name people to resolve
+	trailers []string
+}
+
+// parseOptions extracts git-cocommit flags from args
+// and returns them together with the remaining git commit arguments
+func parseOptions(args []string) (options, []string) {
+	var opts options
+	var rest []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		// Everything after "--" is a pathspec
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+
+		switch {
+		case arg == "--refresh":
+			opts.refresh = true
+		case arg == "--no-prompt":
+			opts.noPrompt = true
+		case arg == "--dry-run":
+			opts.dryRun = true
+		case arg == "--print-trailers":
+			opts.printTrailers = true
+		case (arg == "--with" || arg == "-w") && i+1 < len(args):
+			i++
+			opts.with = append(opts.with, splitCoAuthors(args[i])...)
+		case strings.HasPrefix(arg, "--with="):
+			opts.with = append(opts.with, splitCoAuthors(strings.TrimPrefix(arg, "--with="))...)
+		case arg == "--trailer" && i+1 < len(args):
+			i++
+			opts.trailers = append(opts.trailers, args[i])
+		case strings.HasPrefix(arg, "--trailer="):
+			opts.trailers = append(opts.trailers, strings.TrimPrefix(arg, "--trailer="))
+		case (arg == "--since" || arg == "--max") && i+1 < len(args):
+			i++
+			opts.set(arg[2:], args[i])
+		case strings.HasPrefix(arg, "--since="), strings.HasPrefix(arg, "--max="):
+			name, value, _ := strings.Cut(arg[2:], "=")
+			opts.set(name, value)
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	return opts, rest
+}
+
+// set sets a flag that takes a value
+func (o *options) set(name, value string) {
+	switch name {
+	case "since":
+		o.since = value
+	case "max":
+		o.max = value
+	}
+}
+
+// canPrompt reports whether the user can be asked for input
+// Prompting needs a terminal on standard input and is disabled by --no-prompt.
+func (o options) canPrompt() bool {
+	return !o.noPrompt && isTerminal(os.Stdin)
+}
+
+// getGitConfig gets a git config value, returning an empty string if it is not set
+func getGitConfig(key string) string {
+	cmd := exec.Command("git", "config", "--get", key)
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// getCacheTTL gets the GitHub user cache TTL
+// from the COCOMMIT_CACHE_TTL environment variable or the cocommit.cacheTTL git config
+func getCacheTTL() time.Duration {
+	value := os.Getenv("COCOMMIT_CACHE_TTL")
+	if value == "" {
+		value = getGitConfig("cocommit.cacheTTL")
+	}
+	if value == "" {
+		return github.DefaultCacheTTL
+	}
+
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl < 0 {
+		return github.DefaultCacheTTL
+	}
+	return ttl
+}
+
+// newGitHubClient creates a GitHub client backed by the on-disk user cache
+// A GitHub Enterprise Server instance is used when GITHUB_API_URL or cocommit.github.baseURL is set.
+func newGitHubClient(opts options) (*github.Client, error) {
+	baseURL := getSetting("GITHUB_API_URL", "cocommit.github.baseURL")
+	enterprise := github.IsEnterpriseURL(baseURL)
+
+	// Keep a separate cache per GitHub host
+	host := ""
+	if enterprise {
+		if u, err := url.Parse(baseURL); err == nil {
+			host = u.Hostname()
+		}
+	}
+	var cache *github.Cache
+	if path, err := github.DefaultCachePath(host); err == nil {
+		cache = github.LoadCache(path, getCacheTTL())
+	}
+
+	var client *github.Client
+	if enterprise {
+		var err error
+		client, err = github.NewEnterpriseClient(
+			cache,
+			baseURL,
+			getSetting("GITHUB_UPLOAD_URL", "cocommit.github.uploadURL"),
+			getSetting("GITHUB_NOREPLY_DOMAIN", "cocommit.github.noreplyDomain"),
+		)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		client = github.NewClient(cache)
+	}
+
+	client.Refresh = opts.refresh
+	return client, nil
+}
@@ -0,0 +1,70 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantOpts options
+		wantRest []string
+	}{
+		{
+			name:     "No cocommit flags",
+			args:     []string{"-m", "message"},
+			wantOpts: options{},
+			wantRest: []string{"-m", "message"},
+		},
+		{
+			name:     "Refresh flag",
+			args:     []string{"--refresh", "-m", "message"},
+			wantOpts: options{refresh: true},
+			wantRest: []string{"-m", "message"},
+		},
+		{
+			name:     "History limits",
+			args:     []string{"--since", "2.weeks", "--max=10", "-a"},
+			wantOpts: options{since: "2.weeks", max: "10"},
+			wantRest: []string{"-a"},
+		},
+		{
+			name:     "Explicit co-authors",
+			args:     []string{"--with", "alice", "-w", "Bob Smith <bob@example.com>", "--with=carol,dave", "--no-prompt", "-m", "message"},
+			wantOpts: options{with: []string{"alice", "Bob Smith <bob@example.com>", "carol", "dave"}, noPrompt: true},
+			wantRest: []string{"-m", "message"},
+		},
+		{
+			name:     "Output modes",
+			args:     []string{"--dry-run", "-a", "--print-trailers"},
+			wantOpts: options{dryRun: true, printTrailers: true},
+			wantRest: []string{"-a"},
+		},
+		{
+			name:     "Trailers",
+			args:     []string{"--trailer", "Reviewed-by=alice", "-m", "message", "--trailer=Fixes: #123"},
+			wantOpts: options{trailers: []string{"Reviewed-by=alice", "Fixes: #123"}},
+			wantRest: []string{"-m", "message"},
+		},
+		{
+			name:     "Flags after -- are pathspecs",
+			args:     []string{"-m", "message", "--", "--refresh"},
+			wantOpts: options{},
+			wantRest: []string{"-m", "message", "--", "--refresh"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotOpts, gotRest := parseOptions(tt.args)
+			if !reflect.DeepEqual(gotOpts, tt.wantOpts) {
+				t.Errorf("parseOptions() opts = %+v, want %+v", gotOpts, tt.wantOpts)
+			}
+			if !reflect.DeepEqual(gotRest, tt.wantRest) {
+				t.Errorf("parseOptions() rest = %v, want %v", gotRest, tt.wantRest)
+			}
+		})
+	}
+}
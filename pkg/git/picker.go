@@ -0,0 +1,107 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/mail"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/MITSUBOSHI/cocommit/pkg/picker"
+)
+
+// externalPickers are the supported external fuzzy finders and the arguments for multi-select with a prompt
+var externalPickers = map[string]func(prompt string) []string{
+	"peco": func(prompt string) []string { return []string{"--prompt", prompt} },
+	"fzf":  func(prompt string) []string { return []string{"--multi", "--prompt", prompt + "> "} },
+	"sk":   func(prompt string) []string { return []string{"--multi", "--prompt", prompt + "> "} },
+}
+
+// getPickerName gets the picker from the COCOMMIT_PICKER environment variable or the cocommit.picker git config:
+// builtin (default), peco, fzf, sk or list
+func getPickerName() (string, error) {
+	name := getSetting("COCOMMIT_PICKER", "cocommit.picker")
+	if name == "" {
+		return "builtin", nil
+	}
+	if _, ok := externalPickers[name]; ok || name == "builtin" || name == "list" {
+		return name, nil
+	}
+	return "", fmt.Errorf("unknown picker '%s' (use builtin, peco, fzf, sk or list)", name)
+}
+
+// selectItems selects items with the configured picker
+// The built-in picker is used by default, and a numbered list when there is no terminal.
+func selectItems(items []string, prompt, listPrompt string) ([]string, error) {
+	name, err := getPickerName()
+	if err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case "list":
+		return selectFromList(items, listPrompt)
+	case "builtin":
+	default:
+		if _, err := exec.LookPath(name); err == nil {
+			return selectWithCommand(name, externalPickers[name](prompt), items)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %s is not installed, using the built-in picker\n", name)
+	}
+
+	selected, err := picker.Select(items, picker.Options{Prompt: prompt, Preview: previewIdentity})
+	if errors.Is(err, picker.ErrNoTerminal) {
+		// Standard selection method
+		return selectFromList(items, listPrompt)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(selected) == 0 {
+		return nil, errors.New("no valid selections made")
+	}
+	return selected, nil
+}
+
+// selectWithCommand selects items using incremental search with an external fuzzy finder
+func selectWithCommand(name string, args []string, items []string) ([]string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(strings.Join(items, "\n") + "\n")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+
+	// Get selection results
+	var result []string
+	for _, s := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if s != "" {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+// previewIdentity describes an item of the pickers, such as "Name <email>", "Name <email> (42%)"
+// or "Name <email> [alias]"
+func previewIdentity(item string) string {
+	identity := item
+	if i := strings.LastIndex(item, ">"); i >= 0 {
+		identity = item[:i+1]
+	}
+
+	address, err := mail.ParseAddress(identity)
+	if err != nil || address.Name == "" {
+		return item
+	}
+	preview := fmt.Sprintf("Name: %s  Email: %s", address.Name, address.Address)
+	if strings.Contains(address.Address, "@users.noreply.") {
+		preview += "  (no-reply address)"
+	}
+	return preview
+}
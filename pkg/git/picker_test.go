@@ -0,0 +1,51 @@
+package git
+
+import "testing"
+
+func TestGetPickerName(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"", "builtin", false},
+		{"builtin", "builtin", false},
+		{"fzf", "fzf", false},
+		{"sk", "sk", false},
+		{"peco", "peco", false},
+		{"list", "list", false},
+		{"dmenu", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("COCOMMIT_PICKER", tt.value)
+			got, err := getPickerName()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getPickerName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("getPickerName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreviewIdentity(t *testing.T) {
+	tests := []struct {
+		item string
+		want string
+	}{
+		{"Alice Smith <alice@example.com>", "Name: Alice Smith  Email: alice@example.com"},
+		{"Alice Smith <alice@example.com> (42%)", "Name: Alice Smith  Email: alice@example.com"},
+		{"alice <1+alice@users.noreply.github.com>", "Name: alice  Email: 1+alice@users.noreply.github.com  (no-reply address)"},
+		{"Alice Smith <alice@example.com> [alice]", "Name: Alice Smith  Email: alice@example.com"},
+		{"alice", "alice"},
+	}
+
+	for _, tt := range tests {
+		if got := previewIdentity(tt.item); got != tt.want {
+			t.Errorf("previewIdentity(%q) = %q, want %q", tt.item, got, tt.want)
+		}
+	}
+}
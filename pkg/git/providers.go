@@ -0,0 +1,110 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MITSUBOSHI/cocommit/pkg/gitea"
+	"github.com/MITSUBOSHI/cocommit/pkg/gitlab"
+	"github.com/MITSUBOSHI/cocommit/pkg/provider"
+)
+
+// defaultProviderName is used when neither the handle nor the configuration selects a provider
+const defaultProviderName = "github"
+
+// getDefaultProviderName gets the provider for handles without a "provider:" prefix
+// from the COCOMMIT_PROVIDER environment variable or the cocommit.provider git config
+func getDefaultProviderName() string {
+	if name := os.Getenv("COCOMMIT_PROVIDER"); name != "" {
+		return strings.ToLower(name)
+	}
+	if name := getGitConfig("cocommit.provider"); name != "" {
+		return strings.ToLower(name)
+	}
+	return defaultProviderName
+}
+
+// getNamePolicy gets how co-author names are written in trailers
+// from the COCOMMIT_NAME_FORMAT environment variable or the cocommit.nameFormat git config
+func getNamePolicy() (provider.NamePolicy, error) {
+	return provider.ParseNamePolicy(getSetting("COCOMMIT_NAME_FORMAT", "cocommit.nameFormat"))
+}
+
+// getSetting gets a setting from an environment variable, falling back to a git config key
+func getSetting(envKey, configKey string) string {
+	if value := os.Getenv(envKey); value != "" {
+		return value
+	}
+	return getGitConfig(configKey)
+}
+
+// newProvider creates the identity provider registered under name
+func newProvider(name string, opts options) (provider.Provider, error) {
+	switch name {
+	case "github":
+		return newGitHubClient(opts)
+	case "gitlab":
+		return gitlab.NewClient(
+			getSetting("GITLAB_URL", "cocommit.gitlab.url"),
+			getSetting("GITLAB_NOREPLY_DOMAIN", "cocommit.gitlab.noreplyDomain"),
+		)
+	case "gitea", "forgejo":
+		return gitea.NewClient(name, getSetting(strings.ToUpper(name)+"_URL", "cocommit."+name+".url"))
+	default:
+		return nil, fmt.Errorf("unknown provider '%s' (expected github, gitlab, gitea or forgejo)", name)
+	}
+}
+
+// providerSet holds the providers used by a single run, keyed by name
+// Providers are created up front by prepare, so get is safe to call concurrently afterwards.
+type providerSet struct {
+	opts        options
+	defaultName string
+	providers   map[string]provider.Provider
+}
+
+// newProviderSet creates an empty provider set
+func newProviderSet(opts options) *providerSet {
+	return &providerSet{
+		opts:        opts,
+		defaultName: getDefaultProviderName(),
+		providers:   map[string]provider.Provider{},
+	}
+}
+
+// split returns the provider name and bare handle for a possibly prefixed handle
+func (s *providerSet) split(spec string) (string, string) {
+	name, handle := provider.ParseHandle(spec)
+	if name == "" {
+		name = s.defaultName
+	}
+	return name, handle
+}
+
+// prepare creates the providers needed to resolve handles
+// Literal identities need no provider and are skipped.
+func (s *providerSet) prepare(handles []string) error {
+	for _, spec := range handles {
+		if isLiteralIdentity(spec) {
+			continue
+		}
+		name, _ := s.split(spec)
+		if _, ok := s.providers[name]; ok {
+			continue
+		}
+
+		p, err := newProvider(name, s.opts)
+		if err != nil {
+			return err
+		}
+		s.providers[name] = p
+	}
+	return nil
+}
+
+// get returns the provider and bare handle for a possibly prefixed handle
+func (s *providerSet) get(spec string) (provider.Provider, string) {
+	name, handle := s.split(spec)
+	return s.providers[name], handle
+}
@@ -0,0 +1,104 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MITSUBOSHI/cocommit/pkg/provider"
+)
+
+const (
+	// resolveWorkers is the maximum number of usernames resolved at the same time
+	resolveWorkers = 4
+	// resolveTimeout is the deadline shared by all lookups of a single run
+	resolveTimeout = 15 * time.Second
+)
+
+// resolveFunc resolves a single username into a "Name <email>" identity
+type resolveFunc func(ctx context.Context, username string) (string, error)
+
+// resolveFailure records why a username could not be resolved
+type resolveFailure struct {
+	username string
+	err      error
+}
+
+// resolveError aggregates every username that failed to resolve
+type resolveError struct {
+	failures []resolveFailure
+}
+
+func (e *resolveError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "failed to resolve %d co-author(s):", len(e.failures))
+	for _, failure := range e.failures {
+		fmt.Fprintf(&b, "\n  - %s: %s", failure.username, failureReason(failure.err))
+	}
+	return b.String()
+}
+
+// Unwrap exposes the individual failures to errors.Is and errors.As
+func (e *resolveError) Unwrap() []error {
+	errs := make([]error, len(e.failures))
+	for i, failure := range e.failures {
+		errs[i] = failure.err
+	}
+	return errs
+}
+
+// failureReason describes a lookup error in a few words
+func failureReason(err error) string {
+	switch {
+	case errors.Is(err, provider.ErrNotFound):
+		return "not found"
+	case errors.Is(err, provider.ErrRateLimited):
+		return "rate-limited (set an API token to raise the limit)"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	default:
+		return err.Error()
+	}
+}
+
+// resolveUsernames resolves all usernames concurrently with a bounded number of workers
+// Results keep the order of usernames. If any lookup fails, every failure is reported in one error.
+func resolveUsernames(usernames []string, resolve resolveFunc) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+
+	results := make([]string, len(usernames))
+	errs := make([]error, len(usernames))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(resolveWorkers, len(usernames)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i], errs[i] = resolve(ctx, usernames[i])
+			}
+		}()
+	}
+	for i := range usernames {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	var failures []resolveFailure
+	for i, err := range errs {
+		if err != nil {
+			failures = append(failures, resolveFailure{username: usernames[i], err: err})
+		}
+	}
+	if len(failures) > 0 {
+		return nil, &resolveError{failures: failures}
+	}
+
+	return results, nil
+}
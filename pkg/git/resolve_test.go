@@ -0,0 +1,76 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/MITSUBOSHI/cocommit/pkg/github"
+)
+
+func TestResolveUsernames(t *testing.T) {
+	t.Run("All succeed in order", func(t *testing.T) {
+		usernames := []string{"alice", "bob", "carol", "dave", "erin", "frank"}
+		var running, maxRunning int32
+
+		got, err := resolveUsernames(usernames, func(ctx context.Context, username string) (string, error) {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			return fmt.Sprintf("%s <%s@example.com>", username, username), nil
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		for i, username := range usernames {
+			want := fmt.Sprintf("%s <%s@example.com>", username, username)
+			if got[i] != want {
+				t.Errorf("resolveUsernames()[%d] = %v, want %v", i, got[i], want)
+			}
+		}
+		if maxRunning > resolveWorkers {
+			t.Errorf("Expected at most %d concurrent lookups, got %d", resolveWorkers, maxRunning)
+		}
+	})
+
+	t.Run("Failures are aggregated", func(t *testing.T) {
+		usernames := []string{"alice", "typo", "limited", "slow"}
+
+		_, err := resolveUsernames(usernames, func(ctx context.Context, username string) (string, error) {
+			switch username {
+			case "typo":
+				return "", fmt.Errorf("GitHub user '%s': %w", username, github.ErrUserNotFound)
+			case "limited":
+				return "", fmt.Errorf("%w: 403", github.ErrRateLimited)
+			case "slow":
+				return "", context.DeadlineExceeded
+			}
+			return username + " <" + username + "@example.com>", nil
+		})
+
+		var resolveErr *resolveError
+		if !errors.As(err, &resolveErr) {
+			t.Fatalf("Expected *resolveError, got %v", err)
+		}
+		if len(resolveErr.failures) != 3 {
+			t.Errorf("Expected 3 failures, got %d", len(resolveErr.failures))
+		}
+		for _, want := range []string{"typo: not found", "limited: rate-limited", "slow: timeout"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Expected error to contain %q, got %q", want, err.Error())
+			}
+		}
+		if !errors.Is(err, github.ErrUserNotFound) {
+			t.Errorf("Expected errors.Is(err, ErrUserNotFound)")
+		}
+	})
+}
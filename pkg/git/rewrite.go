@@ -0,0 +1,306 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// backupRefPrefix is where the original tip is kept before rewriting
+const backupRefPrefix = "refs/cocommit/backup/"
+
+// rewriteArgs holds the arguments of git cocommit rewrite
+type rewriteArgs struct {
+	revRange string
+	with     []string
+	force    bool
+}
+
+// parseRewriteArgs parses the arguments of git cocommit rewrite
+// The git-cocommit flags, including --with, are taken by parseOptions as for git cocommit itself.
+// Only --with and --refresh apply to rewrite; the other flags are rejected rather than ignored.
+func parseRewriteArgs(args []string) (options, rewriteArgs, error) {
+	opts, args := parseOptions(args)
+	parsed := rewriteArgs{with: opts.with}
+	for _, flag := range []struct {
+		name string
+		set  bool
+	}{
+		{"--dry-run", opts.dryRun},
+		{"--print-trailers", opts.printTrailers},
+		{"--trailer", len(opts.trailers) > 0},
+		{"--no-prompt", opts.noPrompt},
+		{"--since", opts.since != ""},
+		{"--max", opts.max != ""},
+	} {
+		if flag.set {
+			return opts, parsed, fmt.Errorf("unknown option '%s'", flag.name)
+		}
+	}
+	for _, arg := range args {
+		switch {
+		case arg == "--force" || arg == "-f":
+			parsed.force = true
+		case arg == "--with" || arg == "-w":
+			// parseOptions leaves --with without a value
+			return opts, parsed, fmt.Errorf("option '%s' requires a value", arg)
+		case strings.HasPrefix(arg, "-"):
+			return opts, parsed, fmt.Errorf("unknown option '%s'", arg)
+		case parsed.revRange != "":
+			return opts, parsed, fmt.Errorf("unexpected argument '%s'", arg)
+		default:
+			parsed.revRange = arg
+		}
+	}
+
+	if parsed.revRange == "" || len(parsed.with) == 0 {
+		return opts, parsed, errors.New("usage: git cocommit rewrite <range> --with <username>[,<username>...] [--force]")
+	}
+	return opts, parsed, nil
+}
+
+// Rewrite adds co-authors to the commits in a range of the current branch
+// Usage: git cocommit rewrite <range> --with <username>[,<username>...] [--force]
+func Rewrite(args []string) error {
+	opts, parsed, err := parseRewriteArgs(args)
+	if err != nil {
+		return err
+	}
+
+	// Commits in the range get the co-authors
+	targets, boundaries, err := listRange(parsed.revRange)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("no commits in '%s'", parsed.revRange)
+	}
+
+	// Every commit from the range up to HEAD has to be recreated, since its parents change
+	walk, err := revList(append([]string{"--reverse", "--topo-order", "--parents", "HEAD", "--not"}, boundaries...)...)
+	if err != nil {
+		return err
+	}
+	onBranch := map[string]bool{}
+	for _, line := range walk {
+		onBranch[strings.Fields(line)[0]] = true
+	}
+	for commit := range targets {
+		if !onBranch[commit] {
+			return fmt.Errorf("commit %s is not on the current branch", shortHash(commit))
+		}
+	}
+
+	// Commits reachable from a remote-tracking branch may have been fetched by others
+	unpushed, err := revList(append([]string{"HEAD", "--not", "--remotes"}, boundaries...)...)
+	if err != nil {
+		return err
+	}
+	isUnpushed := map[string]bool{}
+	for _, commit := range unpushed {
+		isUnpushed[commit] = true
+	}
+
+	coAuthors, err := resolveCoAuthors(parsed.with, opts)
+	if err != nil {
+		return err
+	}
+	key, err := getTrailerKey()
+	if err != nil {
+		return err
+	}
+	trailers := coAuthorTrailers(key, coAuthors)
+
+	// Recreate the commits oldest first, mapping each one to its replacement
+	rewritten := map[string]string{}
+	changed := 0
+	for _, line := range walk {
+		fields := strings.Fields(line)
+		commit, parents := fields[0], fields[1:]
+
+		parentsChanged := false
+		for i, parent := range parents {
+			if newParent, ok := rewritten[parent]; ok && newParent != parent {
+				parents[i] = newParent
+				parentsChanged = true
+			}
+		}
+
+		info, err := readCommit(commit)
+		if err != nil {
+			return err
+		}
+		message := info.message
+		if targets[commit] {
+			message = mergeTrailers(info.message, trailers)
+		}
+		if message == info.message && !parentsChanged {
+			rewritten[commit] = commit
+			continue
+		}
+
+		// Refuse to rewrite commits others may have already fetched
+		if !isUnpushed[commit] && !parsed.force {
+			return fmt.Errorf("commit %s has already been pushed (use --force to rewrite it anyway)", shortHash(commit))
+		}
+
+		newCommit, err := info.recreate(parents, message)
+		if err != nil {
+			return err
+		}
+		rewritten[commit] = newCommit
+		if message != info.message {
+			changed++
+		}
+	}
+
+	oldHead, err := revParse("HEAD")
+	if err != nil {
+		return err
+	}
+	newHead := rewritten[oldHead]
+	if changed == 0 || newHead == oldHead {
+		fmt.Println("All commits already credit the co-authors, nothing to rewrite")
+		return nil
+	}
+
+	return updateBranch(oldHead, newHead, changed)
+}
+
+// updateBranch moves the current branch (or a detached HEAD) to newHead, keeping oldHead in a backup ref
+func updateBranch(oldHead, newHead string, changed int) error {
+	ref, name := "HEAD", "HEAD"
+	if out, err := exec.Command("git", "symbolic-ref", "-q", "HEAD").Output(); err == nil {
+		ref = strings.TrimSpace(string(out))
+		name = strings.TrimPrefix(ref, "refs/heads/")
+	}
+
+	backupRef := backupRefPrefix + name
+	if err := exec.Command("git", "update-ref", backupRef, oldHead).Run(); err != nil {
+		return fmt.Errorf("failed to create backup ref %s: %w", backupRef, err)
+	}
+
+	// The trees are unchanged, so the index and working tree stay in sync with the new HEAD
+	cmd := exec.Command("git", "update-ref", "-m", "cocommit: rewrite", ref, newHead, oldHead)
+	if ref == "HEAD" {
+		cmd = exec.Command("git", "update-ref", "--no-deref", "-m", "cocommit: rewrite", ref, newHead, oldHead)
+	}
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to update %s: %w", ref, err)
+	}
+
+	fmt.Printf("Added co-authors to %d commit(s)\n", changed)
+	fmt.Printf("The original commits are kept in %s (restore with 'git reset --hard %s')\n", backupRef, backupRef)
+	return nil
+}
+
+// listRange lists the commits in a revision range and the boundary commits just outside of it
+func listRange(revRange string) (map[string]bool, []string, error) {
+	lines, err := revList("--boundary", revRange, "--")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	targets := map[string]bool{}
+	var boundaries []string
+	for _, line := range lines {
+		if boundary, ok := strings.CutPrefix(line, "-"); ok {
+			boundaries = append(boundaries, boundary)
+		} else {
+			targets[line] = true
+		}
+	}
+	return targets, boundaries, nil
+}
+
+// revList runs git rev-list and returns its output lines
+func revList(args ...string) ([]string, error) {
+	cmd := exec.Command("git", append([]string{"rev-list"}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// revParse resolves a revision to a commit hash
+func revParse(rev string) (string, error) {
+	out, err := exec.Command("git", "rev-parse", "--verify", "-q", rev+"^{commit}").Output()
+	if err != nil {
+		return "", fmt.Errorf("unknown revision '%s'", rev)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// shortHash abbreviates a commit hash for messages
+func shortHash(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
+
+// commitInfo is what is needed to recreate a commit
+type commitInfo struct {
+	tree        string
+	authorName  string
+	authorEmail string
+	authorDate  string
+	message     string
+}
+
+// readCommit reads the tree, authorship and message of a commit
+func readCommit(commit string) (*commitInfo, error) {
+	out, err := exec.Command("git", "show", "-s", "--format=%T%x00%an%x00%ae%x00%ad%x00%B", "--date=raw", commit).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", shortHash(commit), err)
+	}
+
+	fields := strings.SplitN(string(out), "\x00", 5)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("failed to read commit %s", shortHash(commit))
+	}
+	return &commitInfo{
+		tree:        fields[0],
+		authorName:  fields[1],
+		authorEmail: fields[2],
+		authorDate:  fields[3],
+		message:     strings.TrimRight(fields[4], "\n"),
+	}, nil
+}
+
+// recreate creates a commit with the same tree and authorship, and the given parents and message
+func (c *commitInfo) recreate(parents []string, message string) (string, error) {
+	args := []string{"commit-tree", c.tree}
+	for _, parent := range parents {
+		args = append(args, "-p", parent)
+	}
+
+	cmd := exec.Command("git", args...)
+	cmd.Stdin = strings.NewReader(message + "\n")
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME="+c.authorName,
+		"GIT_AUTHOR_EMAIL="+c.authorEmail,
+		"GIT_AUTHOR_DATE="+c.authorDate,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to create commit: %s", strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
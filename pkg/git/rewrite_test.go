@@ -0,0 +1,260 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRewriteArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    rewriteArgs
+		wantErr bool
+	}{
+		{
+			name: "Range with co-authors",
+			args: []string{"HEAD~3..", "--with", "alice,bob"},
+			want: rewriteArgs{revRange: "HEAD~3..", with: []string{"alice", "bob"}},
+		},
+		{
+			name: "Repeated short option and force",
+			args: []string{"-w", "alice", "main..", "-w", "bob", "--force"},
+			want: rewriteArgs{revRange: "main..", with: []string{"alice", "bob"}, force: true},
+		},
+		{
+			name: "Option with value",
+			args: []string{"--with=alice", "main..feature"},
+			want: rewriteArgs{revRange: "main..feature", with: []string{"alice"}},
+		},
+		{
+			name: "Co-author identity with a comma",
+			args: []string{"HEAD~1..", "--with", `"Doe, John" <john@example.com>`, "--refresh"},
+			want: rewriteArgs{revRange: "HEAD~1..", with: []string{`"Doe, John" <john@example.com>`}},
+		},
+		{
+			name:    "Missing co-authors",
+			args:    []string{"HEAD~3.."},
+			wantErr: true,
+		},
+		{
+			name:    "Missing range",
+			args:    []string{"--with", "alice"},
+			wantErr: true,
+		},
+		{
+			name:    "Missing option value",
+			args:    []string{"HEAD~3..", "--with"},
+			wantErr: true,
+		},
+		{
+			name:    "Two ranges",
+			args:    []string{"HEAD~3..", "main..", "--with", "alice"},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown option",
+			args:    []string{"HEAD~3..", "--with", "alice", "--all"},
+			wantErr: true,
+		},
+		{
+			name:    "Dry run",
+			args:    []string{"HEAD~3..", "--with", "alice", "--dry-run"},
+			wantErr: true,
+		},
+		{
+			name:    "Print trailers",
+			args:    []string{"HEAD~3..", "--with", "alice", "--print-trailers"},
+			wantErr: true,
+		},
+		{
+			name:    "Trailer",
+			args:    []string{"HEAD~3..", "--with", "alice", "--trailer", "Reviewed-by=bob"},
+			wantErr: true,
+		},
+		{
+			name:    "No prompt",
+			args:    []string{"HEAD~3..", "--with", "alice", "--no-prompt"},
+			wantErr: true,
+		},
+		{
+			name:    "History options",
+			args:    []string{"HEAD~3..", "--with", "alice", "--since=1.month"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got, err := parseRewriteArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRewriteArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRewriteArgs() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// newTestRepo creates a git repository in a temporary directory, isolated from the user's
+// configuration, and makes it the working directory for the rest of the test
+func newTestRepo(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	testGit(t, "init", "-q", "-b", "main")
+	testGit(t, "config", "user.name", "Test User")
+	testGit(t, "config", "user.email", "test@example.com")
+}
+
+// testGit runs git in the test repository and returns its trimmed output
+func testGit(t *testing.T, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// testCommit commits a change to file with message and returns the commit hash
+func testCommit(t *testing.T, file, message string) string {
+	t.Helper()
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(message + "\n")
+	f.Close()
+	testGit(t, "add", file)
+	testGit(t, "commit", "-q", "-m", message)
+	return testGit(t, "rev-parse", "HEAD")
+}
+
+func TestRewrite(t *testing.T) {
+	const coAuthor = "Alice Smith <alice@example.com>"
+	const trailerLine = coAuthoredByKey + ": " + coAuthor
+
+	t.Run("Adds co-authors to the range", func(t *testing.T) {
+		newTestRepo(t)
+		first := testCommit(t, "a.txt", "First")
+		testCommit(t, "a.txt", "Second")
+		testCommit(t, "a.txt", "Third")
+		oldTree := testGit(t, "rev-parse", "HEAD^{tree}")
+
+		if err := Rewrite([]string{"HEAD~2..", "--with", coAuthor}); err != nil {
+			t.Fatalf("Rewrite() error = %v", err)
+		}
+
+		if got := testGit(t, "log", "-1", "--format=%B", "HEAD"); got != "Third\n\n"+trailerLine {
+			t.Errorf("HEAD message = %q", got)
+		}
+		if got := testGit(t, "log", "-1", "--format=%B", "HEAD~1"); got != "Second\n\n"+trailerLine {
+			t.Errorf("HEAD~1 message = %q", got)
+		}
+		if got := testGit(t, "rev-parse", "HEAD~2"); got != first {
+			t.Errorf("HEAD~2 = %s, want the untouched %s", got, first)
+		}
+		if got := testGit(t, "rev-parse", "HEAD^{tree}"); got != oldTree {
+			t.Errorf("HEAD tree = %s, want %s", got, oldTree)
+		}
+		if got := testGit(t, "log", "-1", "--format=%B", backupRefPrefix+"main"); got != "Third" {
+			t.Errorf("backup ref message = %q, want the original commit", got)
+		}
+	})
+
+	t.Run("Remaps the parents of later merges", func(t *testing.T) {
+		newTestRepo(t)
+		testCommit(t, "a.txt", "Base")
+		testGit(t, "checkout", "-q", "-b", "side")
+		side := testCommit(t, "b.txt", "Side")
+		testGit(t, "checkout", "-q", "main")
+		target := testCommit(t, "a.txt", "Target")
+		testGit(t, "merge", "-q", "--no-ff", "-m", "Merge side", "side")
+
+		if err := Rewrite([]string{target + "^!", "-w", coAuthor}); err != nil {
+			t.Fatalf("Rewrite() error = %v", err)
+		}
+
+		parents := strings.Fields(testGit(t, "log", "-1", "--format=%P", "HEAD"))
+		if len(parents) != 2 {
+			t.Fatalf("HEAD parents = %v, want a merge", parents)
+		}
+		if parents[0] == target {
+			t.Errorf("HEAD first parent is still the original %s", target)
+		}
+		if got := testGit(t, "log", "-1", "--format=%B", parents[0]); got != "Target\n\n"+trailerLine {
+			t.Errorf("first parent message = %q", got)
+		}
+		if parents[1] != side {
+			t.Errorf("HEAD second parent = %s, want the untouched %s", parents[1], side)
+		}
+		if got := testGit(t, "log", "-1", "--format=%B", "HEAD"); got != "Merge side" {
+			t.Errorf("HEAD message = %q, want the merge message unchanged", got)
+		}
+	})
+
+	t.Run("Refuses to rewrite pushed commits", func(t *testing.T) {
+		newTestRepo(t)
+		testCommit(t, "a.txt", "First")
+		testCommit(t, "a.txt", "Second")
+		head := testCommit(t, "a.txt", "Third")
+		testGit(t, "update-ref", "refs/remotes/origin/main", "HEAD~1")
+
+		if err := Rewrite([]string{"HEAD~2..", "--with", coAuthor}); err == nil {
+			t.Fatal("Rewrite() error = nil, want an error for pushed commits")
+		}
+		if got := testGit(t, "rev-parse", "HEAD"); got != head {
+			t.Errorf("HEAD = %s, want it unchanged at %s", got, head)
+		}
+
+		if err := Rewrite([]string{"HEAD~2..", "--with", coAuthor, "--force"}); err != nil {
+			t.Fatalf("Rewrite() with --force error = %v", err)
+		}
+		if got := testGit(t, "log", "-1", "--format=%B", "HEAD~1"); got != "Second\n\n"+trailerLine {
+			t.Errorf("HEAD~1 message = %q", got)
+		}
+	})
+
+	t.Run("Range outside the current branch", func(t *testing.T) {
+		newTestRepo(t)
+		testCommit(t, "a.txt", "Base")
+		testGit(t, "checkout", "-q", "-b", "side")
+		testCommit(t, "b.txt", "Side")
+		testGit(t, "checkout", "-q", "main")
+		testCommit(t, "a.txt", "Main")
+
+		if err := Rewrite([]string{"main..side", "--with", coAuthor}); err == nil {
+			t.Error("Rewrite() error = nil, want an error for commits not on the branch")
+		}
+	})
+	t.Run("Rejects dry run", func(t *testing.T) {
+		newTestRepo(t)
+		testCommit(t, "a.txt", "First")
+		head := testCommit(t, "a.txt", "Second")
+
+		if err := Rewrite([]string{"HEAD~1..", "--with", coAuthor, "--dry-run"}); err == nil {
+			t.Error("Rewrite() error = nil, want an error for --dry-run")
+		}
+		if got := testGit(t, "rev-parse", "HEAD"); got != head {
+			t.Errorf("HEAD = %s, want the untouched %s", got, head)
+		}
+	})
+}
@@ -0,0 +1,181 @@
+package git
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// session is the pair or mob currently working in a repository
+type session struct {
+	CoAuthors []string   `json:"coauthors"`
+	Expires   *time.Time `json:"expires,omitempty"`
+}
+
+// sessionPath gets the path of the session file (.git/cocommit/session)
+func sessionPath() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--git-path", "cocommit/session")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.New("not inside a git repository")
+	}
+	return filepath.Abs(strings.TrimSpace(string(out)))
+}
+
+// expired reports whether the session has expired at now
+func (s *session) expired(now time.Time) bool {
+	return s.Expires != nil && !now.Before(*s.Expires)
+}
+
+// identities returns the co-authors of the session
+// with saves resolved identities, which are used verbatim: parsing them again would drop the
+// login of a "Name (login) <email>" identity. Sessions saved as handles are resolved.
+func (s *session) identities(opts options) ([]string, error) {
+	for _, coAuthor := range s.CoAuthors {
+		if !strings.Contains(coAuthor, "<") {
+			return resolveCoAuthors(s.CoAuthors, opts)
+		}
+	}
+	return s.CoAuthors, nil
+}
+
+// loadSession loads the session of the current repository
+// Returns nil if there is no session or it has expired.
+func loadSession() (*session, error) {
+	path, err := sessionPath()
+	if err != nil {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+
+	var s session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse session %s: %w", path, err)
+	}
+
+	if s.expired(time.Now()) {
+		os.Remove(path)
+		return nil, nil
+	}
+	return &s, nil
+}
+
+// save writes the session to the current repository
+func (s *session) save() error {
+	path, err := sessionPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return nil
+}
+
+// With starts a pairing session with the given co-authors
+// Usage: git cocommit with [--for <duration>] <username>...
+func With(args []string) error {
+	var s session
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		value, isFor := strings.CutPrefix(arg, "--for=")
+		if arg == "--for" && i+1 < len(args) {
+			i++
+			value, isFor = args[i], true
+		}
+		if !isFor {
+			s.CoAuthors = append(s.CoAuthors, splitCoAuthors(arg)...)
+			continue
+		}
+
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration <= 0 {
+			return fmt.Errorf("invalid duration '%s' (e.g. 90m, 4h)", value)
+		}
+		expires := time.Now().Add(duration).Truncate(time.Second)
+		s.Expires = &expires
+	}
+
+	if len(s.CoAuthors) == 0 {
+		return errors.New("usage: git cocommit with [--for <duration>] <username>...")
+	}
+
+	// Resolve now so typos are reported when the session starts rather than at commit time,
+	// and save the identities, so only the team members chosen as present are credited later
+	coAuthors, err := resolveCoAuthors(s.CoAuthors, options{})
+	if err != nil {
+		return err
+	}
+	s.CoAuthors = coAuthors
+
+	if err := s.save(); err != nil {
+		return err
+	}
+
+	fmt.Println("Now committing with:")
+	for _, coAuthor := range coAuthors {
+		fmt.Printf("  %s\n", coAuthor)
+	}
+	if s.Expires != nil {
+		fmt.Printf("Until %s\n", s.Expires.Local().Format(time.DateTime))
+	}
+	return nil
+}
+
+// Solo ends the pairing session
+// Usage: git cocommit solo
+func Solo(args []string) error {
+	path, err := sessionPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove session: %w", err)
+	}
+
+	fmt.Println("Now committing solo")
+	return nil
+}
+
+// Status shows the co-authors that will be added to commits
+// Usage: git cocommit status
+func Status(args []string) error {
+	if env := os.Getenv("GIT_COAUTHORS"); env != "" {
+		fmt.Printf("GIT_COAUTHORS is set and overrides the session: %s\n", env)
+	}
+
+	s, err := loadSession()
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		fmt.Println("No pairing session (use 'git cocommit with <username>...' to start one)")
+		return nil
+	}
+
+	fmt.Printf("Pairing with: %s\n", strings.Join(s.CoAuthors, ", "))
+	if s.Expires != nil {
+		fmt.Printf("Expires: %s (in %s)\n", s.Expires.Local().Format(time.DateTime), time.Until(*s.Expires).Round(time.Minute))
+	}
+	return nil
+}
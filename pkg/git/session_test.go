@@ -0,0 +1,134 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSessionExpired(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		session session
+		want    bool
+	}{
+		{
+			name:    "No expiry",
+			session: session{CoAuthors: []string{"alice"}},
+			want:    false,
+		},
+		{
+			name:    "Expires in the future",
+			session: session{CoAuthors: []string{"alice"}, Expires: &future},
+			want:    false,
+		},
+		{
+			name:    "Expired",
+			session: session{CoAuthors: []string{"alice"}, Expires: &past},
+			want:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.session.expired(now); got != tt.want {
+				t.Errorf("expired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithSavesIdentities(t *testing.T) {
+	newTestRepo(t)
+	testCommit(t, "a.txt", "Base")
+
+	path, err := globalAuthorsPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := (authorRegistry{"alice": "Alice Smith <alice@example.com>"}).save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := With([]string{"alice,Bob Jones <bob@example.com>"}); err != nil {
+		t.Fatalf("With() error = %v", err)
+	}
+
+	s, err := loadSession()
+	if err != nil || s == nil {
+		t.Fatalf("loadSession() = %v, %v", s, err)
+	}
+	want := []string{"Alice Smith <alice@example.com>", "Bob Jones <bob@example.com>"}
+	if !reflect.DeepEqual(s.CoAuthors, want) {
+		t.Errorf("session co-authors = %v, want %v", s.CoAuthors, want)
+	}
+}
+
+func TestSessionRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		coAuthors []string
+		want      []string
+	}{
+		{
+			name:      "Quoted name",
+			coAuthors: []string{`"Doe, Jane" <jane@example.com>`},
+			want:      []string{`"Doe, Jane" <jane@example.com>`},
+		},
+		{
+			name:      "Name with login",
+			coAuthors: []string{"Alice Smith (alice) <alice@example.com>", "Bob Jones <bob@example.com>"},
+			want:      []string{"Alice Smith (alice) <alice@example.com>", "Bob Jones <bob@example.com>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestRepo(t)
+			t.Setenv("GIT_COAUTHORS", "")
+
+			// Saved the way with saves the identities it resolved
+			if err := (&session{CoAuthors: tt.coAuthors}).save(); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := getActiveCoAuthors()
+			if err != nil {
+				t.Fatalf("getActiveCoAuthors() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getActiveCoAuthors() = %v, want %v", got, tt.want)
+			}
+
+			got, err = getCoAuthors(options{noPrompt: true}, true)
+			if err != nil {
+				t.Fatalf("getCoAuthors() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getCoAuthors() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithQuotedName(t *testing.T) {
+	newTestRepo(t)
+	t.Setenv("GIT_COAUTHORS", "")
+
+	if err := With([]string{`"Doe, Jane" <jane@example.com>`}); err != nil {
+		t.Fatalf("With() error = %v", err)
+	}
+
+	got, err := getActiveCoAuthors()
+	if err != nil {
+		t.Fatalf("getActiveCoAuthors() error = %v", err)
+	}
+	want := []string{`"Doe, Jane" <jane@example.com>`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getActiveCoAuthors() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,137 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"os"
+	"strings"
+
+	"github.com/MITSUBOSHI/cocommit/pkg/provider"
+	"golang.org/x/term"
+)
+
+// isTeamHandle reports whether spec names a team ("@org/team") rather than a user
+func isTeamHandle(spec string) bool {
+	_, handle := provider.ParseHandle(spec)
+	return strings.HasPrefix(handle, "@") && strings.Contains(handle, "/")
+}
+
+// getProviderLogin gets the committer's own login on a provider
+// from the cocommit.<provider>.user git config, or github.user for GitHub
+func getProviderLogin(name string) string {
+	if login := getGitConfig("cocommit." + name + ".user"); login != "" {
+		return login
+	}
+	if name == "github" {
+		return getGitConfig("github.user")
+	}
+	return ""
+}
+
+// isTerminal reports whether f is connected to a terminal
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// expandTeams replaces team handles with the handles of the team members
+// The committer is left out, and when prompt is set the user can deselect absent members.
+// The returned set contains the handles that came from a team.
+func expandTeams(specs []string, providers *providerSet, prompt bool) ([]string, map[string]bool, error) {
+	var expanded []string
+	teamMembers := map[string]bool{}
+	seen := map[string]bool{}
+
+	add := func(spec string) {
+		key := strings.ToLower(spec)
+		if !seen[key] {
+			seen[key] = true
+			expanded = append(expanded, spec)
+		}
+	}
+
+	// Explicit handles come first so they are not marked as team members
+	for _, spec := range specs {
+		if !isTeamHandle(spec) {
+			add(spec)
+		}
+	}
+
+	for _, spec := range specs {
+		if !isTeamHandle(spec) {
+			continue
+		}
+
+		name, group := providers.split(spec)
+		p, _ := providers.get(spec)
+
+		ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+		members, err := p.ListMembers(ctx, group)
+		cancel()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list members of '%s': %w", group, err)
+		}
+
+		// Leave out the committer
+		self := getProviderLogin(name)
+		var logins []string
+		for _, member := range members {
+			if !strings.EqualFold(member.Login, self) {
+				logins = append(logins, member.Login)
+			}
+		}
+		if len(logins) == 0 {
+			return nil, nil, fmt.Errorf("team '%s' has no other members", group)
+		}
+
+		// Let the user deselect absent members
+		if prompt {
+			listPrompt := fmt.Sprintf("Members of %s present in this session:", group)
+			logins, err = selectItems(logins, "Select present members", listPrompt)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		for _, login := range logins {
+			member := login
+			if prefix, _ := provider.ParseHandle(spec); prefix != "" {
+				member = prefix + ":" + login
+			}
+			if !seen[strings.ToLower(member)] {
+				teamMembers[member] = true
+			}
+			add(member)
+		}
+	}
+
+	return expanded, teamMembers, nil
+}
+
+// excludeCurrentUser removes co-authors that came from a team and share the committer's email address
+// usernames and coAuthors are parallel slices.
+func excludeCurrentUser(usernames, coAuthors []string, teamMembers map[string]bool) []string {
+	if len(teamMembers) == 0 {
+		return coAuthors
+	}
+
+	currentUser, err := getCurrentGitUser()
+	if err != nil {
+		return coAuthors
+	}
+	current, err := mail.ParseAddress(currentUser)
+	if err != nil {
+		return coAuthors
+	}
+
+	var result []string
+	for i, coAuthor := range coAuthors {
+		if teamMembers[usernames[i]] {
+			if address, err := mail.ParseAddress(coAuthor); err == nil && strings.EqualFold(address.Address, current.Address) {
+				continue
+			}
+		}
+		result = append(result, coAuthor)
+	}
+	return result
+}
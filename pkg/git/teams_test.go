@@ -0,0 +1,97 @@
+package git
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/MITSUBOSHI/cocommit/pkg/provider"
+)
+
+// fakeProvider is a provider backed by fixed team memberships
+type fakeProvider struct {
+	teams map[string][]string
+}
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+func (p *fakeProvider) Resolve(ctx context.Context, handle string) (*provider.Identity, error) {
+	return &provider.Identity{Login: handle, Email: handle + "@example.com"}, nil
+}
+
+func (p *fakeProvider) Search(ctx context.Context, query string) ([]provider.Identity, error) {
+	return nil, nil
+}
+
+func (p *fakeProvider) ListMembers(ctx context.Context, group string) ([]provider.Identity, error) {
+	members, ok := p.teams[group]
+	if !ok {
+		return nil, provider.ErrNotFound
+	}
+	var identities []provider.Identity
+	for _, login := range members {
+		identities = append(identities, provider.Identity{Login: login})
+	}
+	return identities, nil
+}
+
+func TestIsTeamHandle(t *testing.T) {
+	tests := []struct {
+		spec string
+		want bool
+	}{
+		{spec: "alice", want: false},
+		{spec: "@myorg/platform", want: true},
+		{spec: "gitlab:@myorg/platform", want: true},
+		{spec: "@alice", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isTeamHandle(tt.spec); got != tt.want {
+			t.Errorf("isTeamHandle(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestExpandTeams(t *testing.T) {
+	fake := &fakeProvider{teams: map[string][]string{
+		"@myorg/platform": {"alice", "bob", "carol"},
+	}}
+	providers := &providerSet{
+		defaultName: "github",
+		providers:   map[string]provider.Provider{"github": fake, "gitlab": fake},
+	}
+
+	t.Run("Team is expanded after explicit handles", func(t *testing.T) {
+		got, members, err := expandTeams([]string{"@myorg/platform", "bob", "dave"}, providers, false)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		want := []string{"bob", "dave", "alice", "carol"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expandTeams() = %v, want %v", got, want)
+		}
+		if members["bob"] || !members["alice"] || !members["carol"] {
+			t.Errorf("expandTeams() team members = %v", members)
+		}
+	})
+
+	t.Run("Provider prefix is kept", func(t *testing.T) {
+		got, _, err := expandTeams([]string{"gitlab:@myorg/platform"}, providers, false)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		want := []string{"gitlab:alice", "gitlab:bob", "gitlab:carol"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expandTeams() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Unknown team", func(t *testing.T) {
+		if _, _, err := expandTeams([]string{"@myorg/unknown"}, providers, false); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
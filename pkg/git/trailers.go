@@ -0,0 +1,226 @@
+package git
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// trailerPattern matches a "Key: value" trailer line
+var trailerPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*)\s*:\s*(.*)$`)
+
+// trailerKeyPattern matches a trailer key
+var trailerKeyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*$`)
+
+// personTrailerKeys are trailer keys whose values are people, in their conventional casing
+// The values of --trailer options with these keys are resolved like co-authors.
+var personTrailerKeys = []string{
+	"Co-authored-by",
+	"Pair-programmed-with",
+	"Reviewed-by",
+	"Mentored-by",
+	"Signed-off-by",
+	"Acked-by",
+	"Tested-by",
+	"Reported-by",
+	"Suggested-by",
+	"Helped-by",
+}
+
+// gitGeneratedPrefixes are lines git itself adds to messages
+// A block containing one of them only needs 25% trailer lines to count as a trailer block, like in git.
+var gitGeneratedPrefixes = []string{"Signed-off-by: ", "(cherry picked from commit "}
+
+// trailer is a "Key: value" line in a commit message
+type trailer struct {
+	key   string
+	value string
+}
+
+// String formats the trailer as a message line
+func (t trailer) String() string {
+	return t.key + ": " + t.value
+}
+
+// getTrailerKey gets the key of the co-author trailers
+// from the COCOMMIT_TRAILER_KEY environment variable or the cocommit.trailerKey git config,
+// so the casing can match e.g. "Co-authored-by" as GitHub documents it
+func getTrailerKey() (string, error) {
+	key := getSetting("COCOMMIT_TRAILER_KEY", "cocommit.trailerKey")
+	if key == "" {
+		return coAuthoredByKey, nil
+	}
+	if !trailerKeyPattern.MatchString(key) {
+		return "", fmt.Errorf("invalid trailer key '%s'", key)
+	}
+	return key, nil
+}
+
+// parsePersonTrailer parses the value of a --trailer option naming a person, "Key=handle" or "Key: handle"
+// The key is returned in its conventional casing, or in the casing of the co-author trailer key.
+// Options with other keys, or with values that are not handles such as "the whole team" or an identity,
+// are left to git and reported as not ok.
+func parsePersonTrailer(value, coAuthorKey string) (trailer, bool) {
+	i := strings.IndexAny(value, "=:")
+	if i < 0 {
+		return trailer{}, false
+	}
+	key, handle := strings.TrimSpace(value[:i]), strings.TrimSpace(value[i+1:])
+	specs := splitCoAuthors(handle)
+	if len(specs) == 0 || strings.ContainsAny(handle, " \t") {
+		return trailer{}, false
+	}
+	for _, spec := range specs {
+		if isLiteralIdentity(spec) {
+			return trailer{}, false
+		}
+	}
+
+	if strings.EqualFold(key, coAuthorKey) {
+		return trailer{key: coAuthorKey, value: handle}, true
+	}
+	for _, personKey := range personTrailerKeys {
+		if strings.EqualFold(key, personKey) {
+			return trailer{key: personKey, value: handle}, true
+		}
+	}
+	return trailer{}, false
+}
+
+// splitTrailerOptions splits the values of --trailer options into people to resolve
+// and git commit arguments for the other trailers, which git adds itself
+func splitTrailerOptions(values []string, coAuthorKey string) ([]trailer, []string) {
+	var people []trailer
+	var gitArgs []string
+	for _, value := range values {
+		if t, ok := parsePersonTrailer(value, coAuthorKey); ok {
+			people = append(people, t)
+		} else {
+			gitArgs = append(gitArgs, "--trailer", value)
+		}
+	}
+	return people, gitArgs
+}
+
+// coAuthorTrailers creates a trailer with key for each co-author
+func coAuthorTrailers(key string, coAuthors []string) []trailer {
+	var trailers []trailer
+	for _, coAuthor := range coAuthors {
+		trailers = append(trailers, trailer{key: key, value: coAuthor})
+	}
+	return trailers
+}
+
+// formatTrailers formats trailers as message lines
+func formatTrailers(trailers []trailer) string {
+	lines := make([]string, len(trailers))
+	for i, t := range trailers {
+		lines[i] = t.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseTrailer parses a single trailer line
+func parseTrailer(line string) (trailer, bool) {
+	m := trailerPattern.FindStringSubmatch(line)
+	if m == nil {
+		return trailer{}, false
+	}
+	return trailer{key: m[1], value: strings.TrimSpace(m[2])}, true
+}
+
+// splitTrailers splits message into its body and trailer block lines, following git interpret-trailers:
+// the trailer block is the last paragraph (never the subject) when all of its lines are trailers
+// or continuation lines, or when at least 25% are trailers and one of them was generated by git.
+// The trailer block lines are returned unmodified so their order and formatting can be preserved.
+func splitTrailers(message string) (string, []string) {
+	message = strings.TrimRight(message, "\n")
+	lines := strings.Split(message, "\n")
+
+	// Find the start of the last paragraph
+	start := len(lines)
+	for start > 0 && strings.TrimSpace(lines[start-1]) != "" {
+		start--
+	}
+
+	// The first paragraph is the subject and cannot contain trailers
+	subjectEnd := 0
+	for subjectEnd < len(lines) && strings.TrimSpace(lines[subjectEnd]) != "" {
+		subjectEnd++
+	}
+	if start < subjectEnd || start == len(lines) {
+		return message, nil
+	}
+
+	block := lines[start:]
+	trailers, others := 0, 0
+	generated := false
+	for _, line := range block {
+		if isGitGenerated(line) {
+			generated = true
+			trailers++
+		} else if _, ok := parseTrailer(line); ok {
+			trailers++
+		} else if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			// Lines starting with whitespace continue the previous trailer
+			others++
+		}
+	}
+
+	isTrailerBlock := trailers > 0 && (others == 0 || (generated && trailers*3 >= others))
+	if !isTrailerBlock {
+		return message, nil
+	}
+
+	body := strings.TrimRight(strings.Join(lines[:start], "\n"), "\n")
+	return body, block
+}
+
+// isGitGenerated reports whether line was added by git itself
+func isGitGenerated(line string) bool {
+	for _, prefix := range gitGeneratedPrefixes {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// trailerIdentity returns the key used to detect duplicate trailers:
+// the lowercased key and, when the value is an identity, its lowercased email address
+func trailerIdentity(t trailer) string {
+	value := t.value
+	if address, err := mail.ParseAddress(t.value); err == nil {
+		value = address.Address
+	}
+	return strings.ToLower(t.key) + "\x00" + strings.ToLower(value)
+}
+
+// mergeTrailers adds trailers to the trailer block of message
+// Existing trailers keep their order and formatting. Trailers with the same key and
+// email address (compared case-insensitively) as an existing or earlier trailer are skipped.
+func mergeTrailers(message string, trailers []trailer) string {
+	body, block := splitTrailers(message)
+
+	seen := map[string]bool{}
+	for _, line := range block {
+		if t, ok := parseTrailer(line); ok {
+			seen[trailerIdentity(t)] = true
+		}
+	}
+
+	for _, t := range trailers {
+		id := trailerIdentity(t)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		block = append(block, t.String())
+	}
+
+	if len(block) == 0 {
+		return body
+	}
+	return body + "\n\n" + strings.Join(block, "\n")
+}
@@ -0,0 +1,222 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitTrailers(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		wantBody  string
+		wantBlock []string
+	}{
+		{
+			name:      "Subject only",
+			message:   "Fix bug",
+			wantBody:  "Fix bug",
+			wantBlock: nil,
+		},
+		{
+			name:      "Subject that looks like a trailer",
+			message:   "Fix: handle empty input",
+			wantBody:  "Fix: handle empty input",
+			wantBlock: nil,
+		},
+		{
+			name:      "Body without trailers",
+			message:   "Fix bug\n\nThe parser failed on empty input.",
+			wantBody:  "Fix bug\n\nThe parser failed on empty input.",
+			wantBlock: nil,
+		},
+		{
+			name:      "Trailer block",
+			message:   "Fix bug\n\nSigned-off-by: A <a@example.com>\nChange-Id: I123\n",
+			wantBody:  "Fix bug",
+			wantBlock: []string{"Signed-off-by: A <a@example.com>", "Change-Id: I123"},
+		},
+		{
+			name:      "Continuation line",
+			message:   "Fix bug\n\nBody\n\nNote: first line\n  continued\nChange-Id: I123",
+			wantBody:  "Fix bug\n\nBody",
+			wantBlock: []string{"Note: first line", "  continued", "Change-Id: I123"},
+		},
+		{
+			name:      "Mixed paragraph with Signed-off-by",
+			message:   "Fix bug\n\nSome text\nSigned-off-by: A <a@example.com>",
+			wantBody:  "Fix bug",
+			wantBlock: []string{"Some text", "Signed-off-by: A <a@example.com>"},
+		},
+		{
+			name:      "Mixed paragraph without git trailer",
+			message:   "Fix bug\n\nSome text\nChange-Id: I123",
+			wantBody:  "Fix bug\n\nSome text\nChange-Id: I123",
+			wantBlock: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotBody, gotBlock := splitTrailers(tt.message)
+			if gotBody != tt.wantBody {
+				t.Errorf("splitTrailers() body = %q, want %q", gotBody, tt.wantBody)
+			}
+			if !reflect.DeepEqual(gotBlock, tt.wantBlock) {
+				t.Errorf("splitTrailers() block = %q, want %q", gotBlock, tt.wantBlock)
+			}
+		})
+	}
+}
+
+func TestMergeTrailers(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		coAuthors []string
+		want      string
+	}{
+		{
+			name:      "Subject only",
+			message:   "Fix bug",
+			coAuthors: []string{"alice <alice@example.com>"},
+			want:      "Fix bug\n\nCo-Authored-By: alice <alice@example.com>",
+		},
+		{
+			name:      "Merge into existing trailer block",
+			message:   "Fix bug\n\nSigned-off-by: A <a@example.com>\nChange-Id: I123\n",
+			coAuthors: []string{"alice <alice@example.com>"},
+			want:      "Fix bug\n\nSigned-off-by: A <a@example.com>\nChange-Id: I123\nCo-Authored-By: alice <alice@example.com>",
+		},
+		{
+			name:      "Existing co-author with different casing",
+			message:   "Fix bug\n\nCo-authored-by: Alice Smith <Alice@Example.com>",
+			coAuthors: []string{"alice <alice@example.com>", "bob <bob@example.com>"},
+			want:      "Fix bug\n\nCo-authored-by: Alice Smith <Alice@Example.com>\nCo-Authored-By: bob <bob@example.com>",
+		},
+		{
+			name:      "Duplicate co-authors",
+			message:   "Fix bug",
+			coAuthors: []string{"alice <alice@example.com>", "Alice <ALICE@example.com>"},
+			want:      "Fix bug\n\nCo-Authored-By: alice <alice@example.com>",
+		},
+		{
+			name:      "Existing co-author with a quoted name",
+			message:   "Fix bug\n\nCo-Authored-By: \"Doe, John\" <john@example.com>",
+			coAuthors: []string{`"Doe, John" <John@example.com>`},
+			want:      "Fix bug\n\nCo-Authored-By: \"Doe, John\" <john@example.com>",
+		},
+		{
+			name:      "Signed-off-by with same email is not a duplicate",
+			message:   "Fix bug\n\nSigned-off-by: alice <alice@example.com>",
+			coAuthors: []string{"alice <alice@example.com>"},
+			want:      "Fix bug\n\nSigned-off-by: alice <alice@example.com>\nCo-Authored-By: alice <alice@example.com>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeTrailers(tt.message, coAuthorTrailers(coAuthoredByKey, tt.coAuthors))
+			if got != tt.want {
+				t.Errorf("mergeTrailers() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitTrailerOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		values      []string
+		coAuthorKey string
+		wantPeople  []trailer
+		wantGitArgs []string
+	}{
+		{
+			name:        "Person trailers in conventional casing",
+			values:      []string{"reviewed-by=alice", "Signed-off-by: bob,@myorg/platform"},
+			coAuthorKey: coAuthoredByKey,
+			wantPeople:  []trailer{{key: "Reviewed-by", value: "alice"}, {key: "Signed-off-by", value: "bob,@myorg/platform"}},
+			wantGitArgs: nil,
+		},
+		{
+			name:        "Person trailers that are not handles are left to git",
+			values:      []string{"Reviewed-by: the whole team", "Signed-off-by: Bob <bob@example.com>", "Helped-by=carol@example.com", "Acked-by:"},
+			coAuthorKey: coAuthoredByKey,
+			wantPeople:  nil,
+			wantGitArgs: []string{"--trailer", "Reviewed-by: the whole team", "--trailer", "Signed-off-by: Bob <bob@example.com>", "--trailer", "Helped-by=carol@example.com", "--trailer", "Acked-by:"},
+		},
+		{
+			name:        "Co-author key in configured casing",
+			values:      []string{"co-authored-by=alice"},
+			coAuthorKey: "Co-authored-by",
+			wantPeople:  []trailer{{key: "Co-authored-by", value: "alice"}},
+			wantGitArgs: nil,
+		},
+		{
+			name:        "Custom co-author key",
+			values:      []string{"Paired-with=alice"},
+			coAuthorKey: "Paired-with",
+			wantPeople:  []trailer{{key: "Paired-with", value: "alice"}},
+			wantGitArgs: nil,
+		},
+		{
+			name:        "Other trailers are left to git",
+			values:      []string{"Fixes: #123", "Change-Id=I123", "Reviewed-by=alice", "sign"},
+			coAuthorKey: coAuthoredByKey,
+			wantPeople:  []trailer{{key: "Reviewed-by", value: "alice"}},
+			wantGitArgs: []string{"--trailer", "Fixes: #123", "--trailer", "Change-Id=I123", "--trailer", "sign"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotPeople, gotGitArgs := splitTrailerOptions(tt.values, tt.coAuthorKey)
+			if !reflect.DeepEqual(gotPeople, tt.wantPeople) {
+				t.Errorf("splitTrailerOptions() people = %v, want %v", gotPeople, tt.wantPeople)
+			}
+			if !reflect.DeepEqual(gotGitArgs, tt.wantGitArgs) {
+				t.Errorf("splitTrailerOptions() git args = %v, want %v", gotGitArgs, tt.wantGitArgs)
+			}
+		})
+	}
+}
+
+func TestGetTrailerKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Default",
+			env:  "",
+			want: coAuthoredByKey,
+		},
+		{
+			name: "GitHub casing",
+			env:  "Co-authored-by",
+			want: "Co-authored-by",
+		},
+		{
+			name:    "Invalid key",
+			env:     "Paired with:",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("COCOMMIT_TRAILER_KEY", tt.env)
+
+			got, err := getTrailerKey()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getTrailerKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("getTrailerKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package gitea
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/MITSUBOSHI/cocommit/pkg/provider"
+)
+
+// DefaultForgejoURL is the URL of Codeberg, the largest public Forgejo instance
+const DefaultForgejoURL = "https://codeberg.org"
+
+// pageSize is the number of items requested per page
+const pageSize = 50
+
+// Client resolves Gitea and Forgejo users through the REST API (v1)
+// Both share the same API, so one client serves either.
+type Client struct {
+	name       string
+	baseURL    *url.URL
+	token      string
+	httpClient *http.Client
+}
+
+// Client implements provider.Provider
+var _ provider.Provider = (*Client)(nil)
+
+// user is the subset of the Gitea user resource used here
+type user struct {
+	ID       int64  `json:"id"`
+	Login    string `json:"login"`
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+}
+
+// team is the subset of the Gitea team resource used here
+type team struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// NewClient creates a client for the instance at baseURL
+// name is the provider name ("gitea" or "forgejo").
+// Uses authenticated API if GITEA_TOKEN is in the environment variables.
+func NewClient(name, baseURL string) (*Client, error) {
+	if baseURL == "" {
+		if name != "forgejo" {
+			return nil, errors.New("no Gitea URL configured (set GITEA_URL or git config cocommit.gitea.url)")
+		}
+		baseURL = DefaultForgejoURL
+	}
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid %s URL '%s'", name, baseURL)
+	}
+
+	return &Client{
+		name:       name,
+		baseURL:    u,
+		token:      os.Getenv("GITEA_TOKEN"),
+		httpClient: http.DefaultClient,
+	}, nil
+}
+
+// Name returns the provider name
+func (c *Client) Name() string {
+	return c.name
+}
+
+// Resolve gets the identity of a user
+// Users who keep their email private are reported by the API with the
+// instance's no-reply address, which is used as is.
+func (c *Client) Resolve(ctx context.Context, username string) (*provider.Identity, error) {
+	var u user
+	if _, err := c.get(ctx, "users/"+url.PathEscape(username), nil, &u); err != nil {
+		return nil, c.wrapError(username, err)
+	}
+
+	identity := c.identity(u)
+	return &identity, nil
+}
+
+// Search finds users matching query
+func (c *Client) Search(ctx context.Context, query string) ([]provider.Identity, error) {
+	var result struct {
+		Data []user `json:"data"`
+	}
+	if _, err := c.get(ctx, "users/search", url.Values{"q": {query}}, &result); err != nil {
+		return nil, c.wrapError(query, err)
+	}
+
+	var identities []provider.Identity
+	for _, u := range result.Data {
+		identities = append(identities, c.identity(u))
+	}
+	return identities, nil
+}
+
+// ListMembers lists the members of an organization ("org") or a team ("org/team")
+func (c *Client) ListMembers(ctx context.Context, group string) ([]provider.Identity, error) {
+	org, teamName, isTeam := strings.Cut(strings.TrimPrefix(group, "@"), "/")
+
+	path := "orgs/" + url.PathEscape(org) + "/members"
+	if isTeam {
+		teamID, err := c.findTeam(ctx, org, teamName)
+		if err != nil {
+			return nil, c.wrapError(group, err)
+		}
+		path = "teams/" + strconv.FormatInt(teamID, 10) + "/members"
+	}
+
+	var identities []provider.Identity
+	for page := 1; ; page++ {
+		var users []user
+		query := url.Values{"page": {strconv.Itoa(page)}, "limit": {strconv.Itoa(pageSize)}}
+		if _, err := c.get(ctx, path, query, &users); err != nil {
+			return nil, c.wrapError(group, err)
+		}
+
+		for _, u := range users {
+			identities = append(identities, c.identity(u))
+		}
+		if len(users) < pageSize {
+			break
+		}
+	}
+
+	return identities, nil
+}
+
+// findTeam gets the ID of the team named teamName in org
+func (c *Client) findTeam(ctx context.Context, org, teamName string) (int64, error) {
+	var result struct {
+		Data []team `json:"data"`
+	}
+	path := "orgs/" + url.PathEscape(org) + "/teams/search"
+	if _, err := c.get(ctx, path, url.Values{"q": {teamName}}, &result); err != nil {
+		return 0, err
+	}
+
+	for _, t := range result.Data {
+		if strings.EqualFold(t.Name, teamName) {
+			return t.ID, nil
+		}
+	}
+	return 0, provider.ErrNotFound
+}
+
+// identity converts a user into an identity
+func (c *Client) identity(u user) provider.Identity {
+	email := u.Email
+	if email == "" {
+		email = fmt.Sprintf("%s@noreply.%s", u.Login, c.baseURL.Hostname())
+	}
+	return provider.Identity{
+		ID:    u.ID,
+		Login: u.Login,
+		Name:  u.FullName,
+		Email: email,
+	}
+}
+
+// get calls the API endpoint at path and decodes the response into v
+func (c *Client) get(ctx context.Context, path string, query url.Values, v any) (*http.Response, error) {
+	endpoint := c.baseURL.String() + "/api/v1/" + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "token "+c.token)
+	}
+
+	return provider.DoJSON(ctx, c.httpClient, req, v)
+}
+
+// wrapError adds the provider name and target to an API error
+func (c *Client) wrapError(target string, err error) error {
+	return fmt.Errorf("%s '%s': %w", c.name, target, err)
+}
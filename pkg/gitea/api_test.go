@@ -0,0 +1,81 @@
+package gitea
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MITSUBOSHI/cocommit/pkg/provider"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/users/alice":
+			w.Write([]byte(`{"id":1,"login":"alice","full_name":"Alice Smith","email":"alice@noreply.example.org"}`))
+		case "/api/v1/orgs/myorg/teams/search":
+			w.Write([]byte(`{"ok":true,"data":[{"id":7,"name":"Platform"}]}`))
+		case "/api/v1/teams/7/members":
+			w.Write([]byte(`[{"id":1,"login":"alice"},{"id":2,"login":"bob"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestNewClient(t *testing.T) {
+	if _, err := NewClient("gitea", ""); err == nil {
+		t.Errorf("Expected error for gitea without URL")
+	}
+
+	client, err := NewClient("forgejo", "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if client.baseURL.String() != DefaultForgejoURL {
+		t.Errorf("Expected default Forgejo URL, got %v", client.baseURL)
+	}
+}
+
+func TestClientResolve(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	client, err := NewClient("gitea", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identity, err := client.Resolve(context.Background(), "alice")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if identity.Email != "alice@noreply.example.org" || identity.Name != "Alice Smith" {
+		t.Errorf("Resolve() = %+v", identity)
+	}
+
+	if _, err := client.Resolve(context.Background(), "nonexistent"); !errors.Is(err, provider.ErrNotFound) {
+		t.Errorf("Resolve() error = %v, want %v", err, provider.ErrNotFound)
+	}
+}
+
+func TestClientListMembers(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	client, err := NewClient("forgejo", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	members, err := client.ListMembers(context.Background(), "myorg/platform")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(members) != 2 || members[1].Email != "bob@noreply.127.0.0.1" {
+		t.Errorf("ListMembers() = %+v", members)
+	}
+}
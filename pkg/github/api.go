@@ -2,62 +2,287 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/MITSUBOSHI/cocommit/pkg/provider"
 	"github.com/google/go-github/v58/github"
 	"golang.org/x/oauth2"
 )
 
-// GetUserEmail gets an email address from a GitHub username
+var (
+	// ErrUserNotFound is returned when the GitHub user does not exist
+	ErrUserNotFound = provider.ErrNotFound
+	// ErrRateLimited is returned when the GitHub API rate limit has been exceeded
+	ErrRateLimited = provider.ErrRateLimited
+)
+
+// DefaultNoreplyDomain is the domain of no-reply addresses on github.com
+const DefaultNoreplyDomain = "users.noreply.github.com"
+
+// Client implements provider.Provider
+var _ provider.Provider = (*Client)(nil)
+
+// Client looks up GitHub users, optionally through an on-disk cache
+type Client struct {
+	client        *github.Client
+	cache         *Cache
+	noreplyDomain string
+
+	// Refresh bypasses cached entries and always fetches users from the API
+	Refresh bool
+}
+
+// NewClient creates a GitHub client
 // Uses authenticated API if GITHUB_TOKEN is in the environment variables,
-// otherwise uses unauthenticated API (be careful of rate limits)
-func GetUserEmail(username string) (string, error) {
-	var client *github.Client
+// otherwise uses unauthenticated API (be careful of rate limits).
+// cache may be nil to disable caching.
+func NewClient(cache *Cache) *Client {
+	return &Client{
+		client:        github.NewClient(newHTTPClient("GITHUB_TOKEN")),
+		cache:         cache,
+		noreplyDomain: DefaultNoreplyDomain,
+	}
+}
+
+// NewEnterpriseClient creates a client for a GitHub Enterprise Server instance
+// baseURL is the API URL (e.g. https://github.example.com/api/v3/); uploadURL defaults to baseURL.
+// noreplyDomain defaults to "users.noreply.<host>".
+// Uses GH_ENTERPRISE_TOKEN, falling back to GITHUB_TOKEN, for authentication.
+func NewEnterpriseClient(cache *Cache, baseURL, uploadURL, noreplyDomain string) (*Client, error) {
+	if uploadURL == "" {
+		uploadURL = baseURL
+	}
+
+	tokenKey := "GH_ENTERPRISE_TOKEN"
+	if os.Getenv(tokenKey) == "" {
+		tokenKey = "GITHUB_TOKEN"
+	}
+	client, err := github.NewClient(newHTTPClient(tokenKey)).WithEnterpriseURLs(baseURL, uploadURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub Enterprise URL '%s': %w", baseURL, err)
+	}
+
+	if noreplyDomain == "" {
+		noreplyDomain = "users.noreply." + strings.TrimPrefix(client.BaseURL.Hostname(), "api.")
+	}
+
+	return &Client{client: client, cache: cache, noreplyDomain: noreplyDomain}, nil
+}
+
+// IsEnterpriseURL reports whether baseURL points somewhere other than api.github.com
+func IsEnterpriseURL(baseURL string) bool {
+	u, err := url.Parse(baseURL)
+	return baseURL != "" && (err != nil || u.Hostname() != "api.github.com")
+}
 
+// newHTTPClient creates an HTTP client authenticated with the token in the tokenKey environment variable
+// Returns nil (unauthenticated default client) if the token is not set.
+func newHTTPClient(tokenKey string) *http.Client {
 	// Get GitHub Personal Access Token from environment variable
-	token := os.Getenv("GITHUB_TOKEN")
-	if token != "" {
-		// Client with authentication
-		ts := oauth2.StaticTokenSource(
-			&oauth2.Token{AccessToken: token},
-		)
-		tc := oauth2.NewClient(context.Background(), ts)
-		client = github.NewClient(tc)
-	} else {
+	token := os.Getenv(tokenKey)
+	if token == "" {
 		// Client without authentication
-		client = github.NewClient(nil)
+		return nil
 	}
 
+	// Client with authentication
+	ts := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: token},
+	)
+	return oauth2.NewClient(context.Background(), ts)
+}
+
+// GetUserEmail gets an email address from a GitHub username
+// Uses authenticated API if GITHUB_TOKEN is in the environment variables,
+// otherwise uses unauthenticated API (be careful of rate limits)
+func GetUserEmail(username string) (string, error) {
 	// Set timeout for context
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Get user information via GitHub API
-	user, resp, err := client.Users.Get(ctx, username)
+	return NewClient(nil).GetUserEmail(ctx, username)
+}
+
+// GetUserEmail gets an email address from a GitHub username
+// Fresh cache entries are returned without an API call, and stale entries
+// are revalidated with If-None-Match so unchanged users do not count against the rate limit
+func (c *Client) GetUserEmail(ctx context.Context, username string) (string, error) {
+	identity, err := c.Resolve(ctx, username)
 	if err != nil {
-		if resp != nil && resp.StatusCode == http.StatusNotFound {
-			return "", fmt.Errorf("GitHub user '%s' not found", username)
-		}
-		return "", fmt.Errorf("failed to get GitHub user info: %w", err)
+		return "", err
+	}
+	return identity.Email, nil
+}
+
+// Name returns the provider name
+func (c *Client) Name() string {
+	return "github"
+}
+
+// Resolve gets the identity of a GitHub user
+// Users without a public email address get GitHub's no-reply address.
+func (c *Client) Resolve(ctx context.Context, username string) (*provider.Identity, error) {
+	user, err := c.getUser(ctx, username)
+	if err != nil {
+		return nil, err
 	}
 
 	// Get and validate email address
-	email := user.GetEmail()
+	email := user.Email
 	if email == "" {
-		// If public email address is not set
-		// Return GitHub's no-reply format email address (ID+USERNAME@users.noreply.github.com)
-		userID := user.GetID()
-		email = fmt.Sprintf("%d+%s@users.noreply.github.com", userID, username)
+		email = c.noreplyEmail(user.ID, user.Login)
+	}
+
+	return &provider.Identity{
+		ID:    user.ID,
+		Login: user.Login,
+		Name:  user.Name,
+		Email: email,
+	}, nil
+}
+
+// Search finds GitHub users matching query
+func (c *Client) Search(ctx context.Context, query string) ([]provider.Identity, error) {
+	result, resp, err := c.client.Search.Users(ctx, query+" type:user", nil)
+	if err != nil {
+		return nil, wrapAPIError(query, resp, err)
+	}
+
+	var identities []provider.Identity
+	for _, user := range result.Users {
+		identities = append(identities, c.identityFromUser(user))
+	}
+	return identities, nil
+}
+
+// ListMembers lists the members of an organization ("org") or a team ("org/team")
+func (c *Client) ListMembers(ctx context.Context, group string) ([]provider.Identity, error) {
+	org, team, isTeam := strings.Cut(strings.TrimPrefix(group, "@"), "/")
+
+	var identities []provider.Identity
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		var users []*github.User
+		var resp *github.Response
+		var err error
+		if isTeam {
+			users, resp, err = c.client.Teams.ListTeamMembersBySlug(ctx, org, team, &github.TeamListTeamMembersOptions{ListOptions: *opts})
+		} else {
+			users, resp, err = c.client.Organizations.ListMembers(ctx, org, &github.ListMembersOptions{ListOptions: *opts})
+		}
+		if err != nil {
+			return nil, wrapAPIError(group, resp, err)
+		}
+
+		for _, user := range users {
+			identities = append(identities, c.identityFromUser(user))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return identities, nil
+}
+
+// identityFromUser converts a user returned by list endpoints, which never include email addresses
+func (c *Client) identityFromUser(user *github.User) provider.Identity {
+	return provider.Identity{
+		ID:    user.GetID(),
+		Login: user.GetLogin(),
+		Email: c.noreplyEmail(user.GetID(), user.GetLogin()),
+	}
+}
+
+// noreplyEmail returns GitHub's no-reply format email address (ID+USERNAME@users.noreply.github.com)
+func (c *Client) noreplyEmail(id int64, login string) string {
+	return fmt.Sprintf("%d+%s@%s", id, login, c.noreplyDomain)
+}
+
+// getUser returns the user information for username from the cache or the API
+func (c *Client) getUser(ctx context.Context, username string) (*CachedUser, error) {
+	var cached *CachedUser
+	if c.cache != nil && !c.Refresh {
+		entry, fresh := c.cache.Get(username)
+		if fresh {
+			return entry, nil
+		}
+		cached = entry
 	}
 
-	return email, nil
+	req, err := c.client.NewRequest(http.MethodGet, "users/"+username, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub request: %w", err)
+	}
+	if cached != nil && cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	// Get user information via GitHub API
+	user := new(github.User)
+	resp, err := c.client.Do(ctx, req, user)
+	switch {
+	case resp != nil && resp.StatusCode == http.StatusNotModified && cached != nil:
+		// Unchanged since the last fetch
+		cached.FetchedAt = time.Now()
+		c.store(username, *cached)
+		return cached, nil
+	case err != nil:
+		return nil, wrapAPIError(username, resp, err)
+	}
+
+	login := user.GetLogin()
+	if login == "" {
+		login = username
+	}
+	entry := CachedUser{
+		ID:        user.GetID(),
+		Login:     login,
+		Name:      user.GetName(),
+		Email:     user.GetEmail(),
+		ETag:      resp.Header.Get("ETag"),
+		FetchedAt: time.Now(),
+	}
+	c.store(username, entry)
+
+	return &entry, nil
+}
+
+// wrapAPIError classifies a failed API call so callers can tell
+// missing users and rate limiting apart from other failures
+func wrapAPIError(target string, resp *github.Response, err error) error {
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	switch {
+	case resp != nil && resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("GitHub '%s': %w", target, ErrUserNotFound)
+	case errors.As(err, &rateLimitErr), errors.As(err, &abuseErr):
+		return fmt.Errorf("%w: %v", ErrRateLimited, err)
+	default:
+		return fmt.Errorf("failed to get GitHub user info: %w", err)
+	}
+}
+
+// store saves entry to the cache if caching is enabled
+// Cache write failures are ignored because the lookup itself succeeded.
+func (c *Client) store(username string, entry CachedUser) {
+	if c.cache == nil {
+		return
+	}
+	c.cache.Put(username, entry)
+	_ = c.cache.Save()
 }
 
 // FormatCoAuthor creates a Co-Authored-By format string
 // from username and email address
 func FormatCoAuthor(username, email string) string {
-	return fmt.Sprintf("%s <%s>", username, email)
+	return provider.FormatCoAuthor(username, email)
 }
@@ -1,6 +1,7 @@
 package github
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -130,3 +131,68 @@ func TestGetUserEmail(t *testing.T) {
 		})
 	}
 }
+
+func TestNewEnterpriseClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/users/testuser" {
+			t.Errorf("Expected request to /api/v3/users/testuser, got %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(&github.User{
+			ID:    github.Int64(7),
+			Login: github.String("testuser"),
+		})
+	}))
+	defer server.Close()
+
+	t.Setenv("GH_ENTERPRISE_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+
+	tests := []struct {
+		name          string
+		noreplyDomain string
+		want          string
+	}{
+		{
+			name:          "Derived no-reply domain",
+			noreplyDomain: "",
+			want:          "7+testuser@users.noreply.127.0.0.1",
+		},
+		{
+			name:          "Configured no-reply domain",
+			noreplyDomain: "users.noreply.github.example.com",
+			want:          "7+testuser@users.noreply.github.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewEnterpriseClient(nil, server.URL, "", tt.noreplyDomain)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			got, err := client.GetUserEmail(context.Background(), "testuser")
+			if err != nil || got != tt.want {
+				t.Errorf("GetUserEmail() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsEnterpriseURL(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		want    bool
+	}{
+		{baseURL: "", want: false},
+		{baseURL: "https://api.github.com", want: false},
+		{baseURL: "https://api.github.com/", want: false},
+		{baseURL: "https://github.example.com/api/v3/", want: true},
+	}
+
+	for _, tt := range tests {
+		if got := IsEnterpriseURL(tt.baseURL); got != tt.want {
+			t.Errorf("IsEnterpriseURL(%q) = %v, want %v", tt.baseURL, got, tt.want)
+		}
+	}
+}
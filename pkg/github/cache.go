@@ -0,0 +1,126 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL is how long a cached user is trusted before it is revalidated
+const DefaultCacheTTL = 24 * time.Hour
+
+// CachedUser is a GitHub user entry stored in the on-disk cache
+type CachedUser struct {
+	ID        int64     `json:"id"`
+	Login     string    `json:"login"`
+	Name      string    `json:"name,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	ETag      string    `json:"etag,omitempty"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// Cache is an on-disk cache of GitHub users keyed by username
+type Cache struct {
+	path    string
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]*CachedUser
+}
+
+// DefaultCachePath returns the default cache file location
+// ($XDG_CACHE_HOME/cocommit/github-users.json or the platform user cache directory)
+// Each GitHub Enterprise host gets a separate file; host is empty for github.com.
+func DefaultCachePath(host string) (string, error) {
+	cacheDir := os.Getenv("XDG_CACHE_HOME")
+	if cacheDir == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get cache directory: %w", err)
+		}
+		cacheDir = dir
+	}
+	name := "github-users.json"
+	if host != "" {
+		name = "github-users-" + host + ".json"
+	}
+	return filepath.Join(cacheDir, "cocommit", name), nil
+}
+
+// LoadCache reads the cache file at path
+// A missing or unreadable cache is treated as empty, since it can always be rebuilt.
+func LoadCache(path string, ttl time.Duration) *Cache {
+	cache := &Cache{
+		path:    path,
+		ttl:     ttl,
+		entries: map[string]*CachedUser{},
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache.entries); err != nil {
+		cache.entries = map[string]*CachedUser{}
+	}
+
+	return cache
+}
+
+// Get returns the cached entry for username and whether it is still within the TTL
+func (c *Cache) Get(username string) (entry *CachedUser, fresh bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[strings.ToLower(username)]
+	if !ok {
+		return nil, false
+	}
+	copied := *entry
+	return &copied, time.Since(entry.FetchedAt) < c.ttl
+}
+
+// Put stores an entry for username
+func (c *Cache) Put(username string, entry CachedUser) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[strings.ToLower(username)] = &entry
+}
+
+// Save writes the cache back to disk
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// Write to a temporary file first so a concurrent reader never sees a partial cache
+	tempFile, err := os.CreateTemp(filepath.Dir(c.path), ".github-users-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create cache file: %w", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	if _, err := tempFile.Write(data); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+
+	if err := os.Rename(tempFile.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to replace cache file: %w", err)
+	}
+	return nil
+}
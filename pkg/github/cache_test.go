@@ -0,0 +1,115 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v58/github"
+)
+
+// newTestClient creates a Client that talks to server
+func newTestClient(t *testing.T, server *httptest.Server, cache *Cache) *Client {
+	t.Helper()
+	t.Setenv("GITHUB_TOKEN", "")
+
+	client := NewClient(cache)
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.client.BaseURL = baseURL
+	return client
+}
+
+func TestCacheSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cocommit", "github-users.json")
+
+	cache := LoadCache(path, time.Hour)
+	cache.Put("TestUser", CachedUser{ID: 1, Login: "testuser", FetchedAt: time.Now()})
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	loaded := LoadCache(path, time.Hour)
+	entry, fresh := loaded.Get("testuser")
+	if entry == nil || entry.ID != 1 || !fresh {
+		t.Errorf("Get() = %v, %v", entry, fresh)
+	}
+
+	expired := LoadCache(path, 0)
+	if _, fresh := expired.Get("testuser"); fresh {
+		t.Errorf("Expected entry to be stale with zero TTL")
+	}
+}
+
+func TestClientGetUserEmailCache(t *testing.T) {
+	requests := 0
+	conditional := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			conditional++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		json.NewEncoder(w).Encode(&github.User{
+			ID:    github.Int64(42),
+			Login: github.String("testuser"),
+		})
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "github-users.json")
+	want := "42+testuser@users.noreply.github.com"
+
+	t.Run("First lookup fetches from the API", func(t *testing.T) {
+		client := newTestClient(t, server, LoadCache(path, time.Hour))
+		got, err := client.GetUserEmail(context.Background(), "testuser")
+		if err != nil || got != want {
+			t.Errorf("GetUserEmail() = %v, %v, want %v", got, err, want)
+		}
+		if requests != 1 {
+			t.Errorf("Expected 1 request, got %d", requests)
+		}
+	})
+
+	t.Run("Fresh entry is served from the cache", func(t *testing.T) {
+		client := newTestClient(t, server, LoadCache(path, time.Hour))
+		got, err := client.GetUserEmail(context.Background(), "testuser")
+		if err != nil || got != want {
+			t.Errorf("GetUserEmail() = %v, %v, want %v", got, err, want)
+		}
+		if requests != 1 {
+			t.Errorf("Expected 1 request, got %d", requests)
+		}
+	})
+
+	t.Run("Stale entry is revalidated", func(t *testing.T) {
+		client := newTestClient(t, server, LoadCache(path, 0))
+		got, err := client.GetUserEmail(context.Background(), "testuser")
+		if err != nil || got != want {
+			t.Errorf("GetUserEmail() = %v, %v, want %v", got, err, want)
+		}
+		if conditional != 1 {
+			t.Errorf("Expected 1 conditional request, got %d", conditional)
+		}
+	})
+
+	t.Run("Refresh bypasses the cache", func(t *testing.T) {
+		client := newTestClient(t, server, LoadCache(path, time.Hour))
+		client.Refresh = true
+		if _, err := client.GetUserEmail(context.Background(), "testuser"); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if requests != 3 || conditional != 1 {
+			t.Errorf("Expected an unconditional request, got %d requests (%d conditional)", requests, conditional)
+		}
+	})
+}
@@ -0,0 +1,160 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/MITSUBOSHI/cocommit/pkg/provider"
+)
+
+// DefaultBaseURL is the URL of gitlab.com
+const DefaultBaseURL = "https://gitlab.com"
+
+// Client resolves GitLab users through the REST API (v4)
+type Client struct {
+	baseURL       *url.URL
+	noreplyDomain string
+	token         string
+	httpClient    *http.Client
+}
+
+// Client implements provider.Provider
+var _ provider.Provider = (*Client)(nil)
+
+// user is the subset of the GitLab user resource used here
+type user struct {
+	ID          int64  `json:"id"`
+	Username    string `json:"username"`
+	Name        string `json:"name"`
+	PublicEmail string `json:"public_email"`
+}
+
+// NewClient creates a GitLab client for the instance at baseURL
+// Uses authenticated API if GITLAB_TOKEN is in the environment variables.
+// noreplyDomain may be empty to use "users.noreply.<host>".
+func NewClient(baseURL, noreplyDomain string) (*Client, error) {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid GitLab URL '%s'", baseURL)
+	}
+	if noreplyDomain == "" {
+		noreplyDomain = "users.noreply." + u.Hostname()
+	}
+
+	return &Client{
+		baseURL:       u,
+		noreplyDomain: noreplyDomain,
+		token:         os.Getenv("GITLAB_TOKEN"),
+		httpClient:    http.DefaultClient,
+	}, nil
+}
+
+// Name returns the provider name
+func (c *Client) Name() string {
+	return "gitlab"
+}
+
+// Resolve gets the identity of a GitLab user
+// Users without a public email address get GitLab's no-reply address (ID-USERNAME@users.noreply.<host>).
+func (c *Client) Resolve(ctx context.Context, username string) (*provider.Identity, error) {
+	var users []user
+	query := url.Values{"username": {username}}
+	if _, err := c.get(ctx, "users", query, &users); err != nil {
+		return nil, c.wrapError(username, err)
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("GitLab '%s': %w", username, provider.ErrNotFound)
+	}
+
+	// The users endpoint omits public_email, so fetch the single user resource
+	var detail user
+	if _, err := c.get(ctx, "users/"+strconv.FormatInt(users[0].ID, 10), nil, &detail); err != nil {
+		return nil, c.wrapError(username, err)
+	}
+
+	identity := c.identity(detail)
+	return &identity, nil
+}
+
+// Search finds GitLab users matching query
+func (c *Client) Search(ctx context.Context, query string) ([]provider.Identity, error) {
+	var users []user
+	if _, err := c.get(ctx, "users", url.Values{"search": {query}}, &users); err != nil {
+		return nil, c.wrapError(query, err)
+	}
+
+	var identities []provider.Identity
+	for _, u := range users {
+		identities = append(identities, c.identity(u))
+	}
+	return identities, nil
+}
+
+// ListMembers lists the members of a group, including inherited members
+// group is the full path of the group (e.g. "myorg/platform")
+func (c *Client) ListMembers(ctx context.Context, group string) ([]provider.Identity, error) {
+	path := "groups/" + url.PathEscape(strings.TrimPrefix(group, "@")) + "/members/all"
+
+	var identities []provider.Identity
+	page := "1"
+	for page != "" {
+		var users []user
+		query := url.Values{"per_page": {"100"}, "page": {page}}
+		resp, err := c.get(ctx, path, query, &users)
+		if err != nil {
+			return nil, c.wrapError(group, err)
+		}
+
+		for _, u := range users {
+			identities = append(identities, c.identity(u))
+		}
+		page = resp.Header.Get("X-Next-Page")
+	}
+
+	return identities, nil
+}
+
+// identity converts a GitLab user into an identity
+func (c *Client) identity(u user) provider.Identity {
+	email := u.PublicEmail
+	if email == "" {
+		email = fmt.Sprintf("%d-%s@%s", u.ID, u.Username, c.noreplyDomain)
+	}
+	return provider.Identity{
+		ID:    u.ID,
+		Login: u.Username,
+		Name:  u.Name,
+		Email: email,
+	}
+}
+
+// get calls the API endpoint at path and decodes the response into v
+func (c *Client) get(ctx context.Context, path string, query url.Values, v any) (*http.Response, error) {
+	endpoint := c.baseURL.String() + "/api/v4/" + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	}
+
+	return provider.DoJSON(ctx, c.httpClient, req, v)
+}
+
+// wrapError adds the GitLab target to an API error
+func (c *Client) wrapError(target string, err error) error {
+	return fmt.Errorf("GitLab '%s': %w", target, err)
+}
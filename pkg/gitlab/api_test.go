@@ -0,0 +1,107 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MITSUBOSHI/cocommit/pkg/provider"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v4/users":
+			switch r.URL.Query().Get("username") {
+			case "alice":
+				w.Write([]byte(`[{"id":1,"username":"alice","name":"Alice Smith"}]`))
+			case "bob":
+				w.Write([]byte(`[{"id":2,"username":"bob","name":"Bob Jones"}]`))
+			default:
+				w.Write([]byte(`[]`))
+			}
+		case "/api/v4/users/1":
+			w.Write([]byte(`{"id":1,"username":"alice","name":"Alice Smith","public_email":"alice@example.com"}`))
+		case "/api/v4/users/2":
+			w.Write([]byte(`{"id":2,"username":"bob","name":"Bob Jones","public_email":""}`))
+		case "/api/v4/groups/myorg/platform/members/all":
+			if r.URL.Query().Get("page") == "1" {
+				w.Header().Set("X-Next-Page", "2")
+				w.Write([]byte(`[{"id":1,"username":"alice","name":"Alice Smith"}]`))
+			} else {
+				w.Write([]byte(`[{"id":2,"username":"bob","name":"Bob Jones"}]`))
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestClientResolve(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "users.noreply.gitlab.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		want     string
+		wantErr  error
+	}{
+		{
+			name:     "Public email available",
+			username: "alice",
+			want:     "alice@example.com",
+			wantErr:  nil,
+		},
+		{
+			name:     "No public email",
+			username: "bob",
+			want:     "2-bob@users.noreply.gitlab.example.com",
+			wantErr:  nil,
+		},
+		{
+			name:     "User not found",
+			username: "nonexistent",
+			want:     "",
+			wantErr:  provider.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := client.Resolve(context.Background(), tt.username)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Resolve() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && identity.Email != tt.want {
+				t.Errorf("Resolve() email = %v, want %v", identity.Email, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientListMembers(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	members, err := client.ListMembers(context.Background(), "myorg/platform")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(members) != 2 || members[0].Login != "alice" || members[1].Login != "bob" {
+		t.Errorf("ListMembers() = %+v", members)
+	}
+}
@@ -0,0 +1,66 @@
+package picker
+
+import (
+	"sort"
+	"unicode"
+)
+
+// match reports whether the runes of query appear in item in order, ignoring case,
+// and scores the match: lower is better
+// The score favours matches whose runes are close together, then matches that start early.
+func match(item string, query []rune) (int, bool) {
+	if len(query) == 0 {
+		return 0, true
+	}
+
+	runes := []rune(item)
+	best, found := 0, false
+	for start := range runes {
+		if !equalFold(runes[start], query[0]) {
+			continue
+		}
+
+		// Match the rest of the query greedily from this start
+		q, end := 1, start
+		for i := start + 1; i < len(runes) && q < len(query); i++ {
+			if equalFold(runes[i], query[q]) {
+				q++
+				end = i
+			}
+		}
+		if q < len(query) {
+			// Later starts cannot match either
+			break
+		}
+
+		gaps := end - start + 1 - len(query)
+		score := gaps*1000 + start
+		if !found || score < best {
+			best, found = score, true
+		}
+	}
+	return best, found
+}
+
+// equalFold compares two runes ignoring case
+func equalFold(a, b rune) bool {
+	return unicode.ToLower(a) == unicode.ToLower(b)
+}
+
+// filter returns the indexes of the items matching query, best matches first
+// Items that match equally well keep their original order.
+func filter(items []string, query []rune) []int {
+	var matches []int
+	scores := map[int]int{}
+	for i, item := range items {
+		if score, ok := match(item, query); ok {
+			matches = append(matches, i)
+			scores[i] = score
+		}
+	}
+
+	sort.SliceStable(matches, func(a, b int) bool {
+		return scores[matches[a]] < scores[matches[b]]
+	})
+	return matches
+}
@@ -0,0 +1,59 @@
+package picker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		item  string
+		query string
+		want  bool
+	}{
+		{"Empty query", "alice <alice@example.com>", "", true},
+		{"Substring", "alice <alice@example.com>", "lic", true},
+		{"Subsequence", "alice <alice@example.com>", "aex", true},
+		{"Ignores case", "Alice <alice@example.com>", "ALI", true},
+		{"Out of order", "alice <alice@example.com>", "@<", false},
+		{"Missing rune", "alice <alice@example.com>", "z", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := match(tt.item, []rune(tt.query)); got != tt.want {
+				t.Errorf("match(%q, %q) = %v, want %v", tt.item, tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	items := []string{
+		"bob <b@example.com>",
+		"barbara <barb@example.com>",
+		"carol <c@example.com>",
+		"rob <rob@example.com>",
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{"Empty query keeps order", "", []int{0, 1, 2, 3}},
+		{"Closer matches first", "bb", []int{0, 1, 3}},
+		{"Earlier matches first among equals", "ob", []int{0, 3}},
+		{"No matches", "zz", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filter(items, []rune(tt.query))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filter(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
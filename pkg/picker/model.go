@@ -0,0 +1,250 @@
+package picker
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// keyCode identifies a key press
+type keyCode int
+
+const (
+	keyRune keyCode = iota
+	keyEnter
+	keyBackspace
+	keyClear
+	keyUp
+	keyDown
+	keyToggle
+	keyToggleDown
+	keyCancel
+)
+
+// key is a key press, with the typed rune for keyRune
+type key struct {
+	code keyCode
+	r    rune
+}
+
+// action is what the picker does after a key press
+type action int
+
+const (
+	actionNone action = iota
+	actionConfirm
+	actionCancel
+)
+
+// parseKeys decodes the bytes read from a terminal in raw mode into key presses
+// Unknown control characters and escape sequences are ignored.
+func parseKeys(b []byte) []key {
+	var keys []key
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b:
+			if len(b) == 1 {
+				// A lone Escape
+				return append(keys, key{code: keyCancel})
+			}
+			if len(b) >= 3 && (b[1] == '[' || b[1] == 'O') {
+				switch b[2] {
+				case 'A':
+					keys = append(keys, key{code: keyUp})
+				case 'B':
+					keys = append(keys, key{code: keyDown})
+				}
+				// Skip the rest of the sequence, which ends with a letter or '~'
+				i := 2
+				for i < len(b) && !(b[i] >= 'A' && b[i] <= 'Z' || b[i] >= 'a' && b[i] <= 'z' || b[i] == '~') {
+					i++
+				}
+				b = b[min(i+1, len(b)):]
+				continue
+			}
+			b = b[2:]
+			continue
+		case c == '\r' || c == '\n':
+			keys = append(keys, key{code: keyEnter})
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, key{code: keyBackspace})
+		case c == 0x15: // Ctrl-U
+			keys = append(keys, key{code: keyClear})
+		case c == 0x10: // Ctrl-P
+			keys = append(keys, key{code: keyUp})
+		case c == 0x0e: // Ctrl-N
+			keys = append(keys, key{code: keyDown})
+		case c == ' ':
+			keys = append(keys, key{code: keyToggle})
+		case c == '\t':
+			keys = append(keys, key{code: keyToggleDown})
+		case c == 0x03 || c == 0x04: // Ctrl-C, Ctrl-D
+			keys = append(keys, key{code: keyCancel})
+		case c < 0x20:
+			// Other control characters
+		default:
+			r, size := utf8.DecodeRune(b)
+			keys = append(keys, key{code: keyRune, r: r})
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
+
+// model is the state of the picker
+type model struct {
+	items  []string
+	opts   Options
+	height int
+
+	query []rune
+	// matches holds the indexes of the items matching the query
+	matches []int
+	// cursor is the highlighted position in matches
+	cursor int
+	// offset is the first position in matches on screen
+	offset int
+	// selected holds the toggled item indexes in the order they were toggled
+	selected []int
+}
+
+// newModel creates the picker state for items
+func newModel(items []string, opts Options, height int) *model {
+	m := &model{items: items, opts: opts, height: height}
+	m.matches = filter(items, nil)
+	return m
+}
+
+// update applies a key press
+func (m *model) update(k key) action {
+	switch k.code {
+	case keyRune:
+		m.setQuery(append(m.query, k.r))
+	case keyBackspace:
+		if len(m.query) > 0 {
+			m.setQuery(m.query[:len(m.query)-1])
+		}
+	case keyClear:
+		m.setQuery(nil)
+	case keyUp:
+		m.move(-1)
+	case keyDown:
+		m.move(1)
+	case keyToggle:
+		m.toggle()
+	case keyToggleDown:
+		m.toggle()
+		m.move(1)
+	case keyEnter:
+		return actionConfirm
+	case keyCancel:
+		return actionCancel
+	}
+	return actionNone
+}
+
+// setQuery changes the query and filters the items again
+func (m *model) setQuery(query []rune) {
+	m.query = query
+	m.matches = filter(m.items, query)
+	m.cursor, m.offset = 0, 0
+}
+
+// move moves the cursor by delta, scrolling to keep it on screen
+func (m *model) move(delta int) {
+	if len(m.matches) == 0 {
+		return
+	}
+	m.cursor = max(0, min(len(m.matches)-1, m.cursor+delta))
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.height {
+		m.offset = m.cursor - m.height + 1
+	}
+}
+
+// toggle selects or deselects the highlighted item
+func (m *model) toggle() {
+	if len(m.matches) == 0 {
+		return
+	}
+	item := m.matches[m.cursor]
+	for i, selected := range m.selected {
+		if selected == item {
+			m.selected = append(m.selected[:i], m.selected[i+1:]...)
+			return
+		}
+	}
+	m.selected = append(m.selected, item)
+}
+
+// isSelected reports whether the item at index is selected
+func (m *model) isSelected(index int) bool {
+	for _, selected := range m.selected {
+		if selected == index {
+			return true
+		}
+	}
+	return false
+}
+
+// result returns the selected items, or the highlighted item if none is selected
+func (m *model) result() []string {
+	var result []string
+	for _, index := range m.selected {
+		result = append(result, m.items[index])
+	}
+	if len(result) == 0 && len(m.matches) > 0 {
+		result = append(result, m.items[m.matches[m.cursor]])
+	}
+	return result
+}
+
+// render draws the picker as lines no wider than width
+func (m *model) render(width int) []string {
+	lines := []string{truncate(m.opts.Prompt+"> "+string(m.query), width)}
+
+	for i := m.offset; i < len(m.matches) && i < m.offset+m.height; i++ {
+		index := m.matches[i]
+		cursor, mark := "  ", " "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		if m.isSelected(index) {
+			mark = "*"
+		}
+		line := truncate(cursor+mark+" "+m.items[index], width)
+		if i == m.cursor {
+			// Reverse video for the highlighted item
+			line = "\x1b[7m" + line + "\x1b[0m"
+		}
+		lines = append(lines, line)
+	}
+
+	status := fmt.Sprintf("  %d/%d", len(m.matches), len(m.items))
+	if len(m.selected) > 0 {
+		status += fmt.Sprintf(" (%d selected)", len(m.selected))
+	}
+	status += "  space: select, enter: confirm, esc: cancel"
+	lines = append(lines, "\x1b[2m"+truncate(status, width)+"\x1b[0m")
+
+	if m.opts.Preview != nil && len(m.matches) > 0 {
+		preview := m.opts.Preview(m.items[m.matches[m.cursor]])
+		lines = append(lines, "\x1b[2m"+truncate("  "+preview, width)+"\x1b[0m")
+	}
+	return lines
+}
+
+// truncate shortens s to at most width runes, so lines never wrap
+func truncate(s string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	if width == 1 {
+		return string(runes[:1])
+	}
+	return string(runes[:width-1]) + "…"
+}
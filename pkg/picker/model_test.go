@@ -0,0 +1,149 @@
+package picker
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []key
+	}{
+		{"Typed text", "ab", []key{{keyRune, 'a'}, {keyRune, 'b'}}},
+		{"Multibyte rune", "é", []key{{keyRune, 'é'}}},
+		{"Arrow keys", "\x1b[A\x1b[B\x1bOB", []key{{code: keyUp}, {code: keyDown}, {code: keyDown}}},
+		{"Other escape sequences are ignored", "\x1b[3~a", []key{{keyRune, 'a'}}},
+		{"Lone escape", "\x1b", []key{{code: keyCancel}}},
+		{"Controls", " \t\r\x7f\x15\x03", []key{{code: keyToggle}, {code: keyToggleDown}, {code: keyEnter}, {code: keyBackspace}, {code: keyClear}, {code: keyCancel}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseKeys([]byte(tt.input))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseKeys(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModel(t *testing.T) {
+	items := []string{"alice", "bob", "carol", "dave"}
+
+	tests := []struct {
+		name       string
+		input      string
+		wantAction action
+		want       []string
+	}{
+		{
+			name:       "Enter picks the highlighted item",
+			input:      "\r",
+			wantAction: actionConfirm,
+			want:       []string{"alice"},
+		},
+		{
+			name:       "Filter and pick",
+			input:      "car\r",
+			wantAction: actionConfirm,
+			want:       []string{"carol"},
+		},
+		{
+			name:       "Multi-select in selection order",
+			input:      "\x1b[B\x1b[B \x1b[A\x1b[A \r",
+			wantAction: actionConfirm,
+			want:       []string{"carol", "alice"},
+		},
+		{
+			name:       "Tab selects and moves down",
+			input:      "\t\t\r",
+			wantAction: actionConfirm,
+			want:       []string{"alice", "bob"},
+		},
+		{
+			name:       "Toggling twice deselects",
+			input:      "  \x1b[B\r",
+			wantAction: actionConfirm,
+			want:       []string{"bob"},
+		},
+		{
+			name:       "Selection survives filtering",
+			input:      "bob \x15dave \r",
+			wantAction: actionConfirm,
+			want:       []string{"bob", "dave"},
+		},
+		{
+			name:       "Backspace widens the filter",
+			input:      "dx\x7f\r",
+			wantAction: actionConfirm,
+			want:       []string{"dave"},
+		},
+		{
+			name:       "No match",
+			input:      "zz\r",
+			wantAction: actionConfirm,
+			want:       nil,
+		},
+		{
+			name:       "Cancel",
+			input:      "\x03",
+			wantAction: actionCancel,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newModel(items, Options{}, 2)
+			got := actionNone
+			for _, k := range parseKeys([]byte(tt.input)) {
+				if got = m.update(k); got != actionNone {
+					break
+				}
+			}
+			if got != tt.wantAction {
+				t.Fatalf("update() = %v, want %v", got, tt.wantAction)
+			}
+			if got == actionConfirm && !reflect.DeepEqual(m.result(), tt.want) {
+				t.Errorf("result() = %v, want %v", m.result(), tt.want)
+			}
+		})
+	}
+}
+
+func TestModelScroll(t *testing.T) {
+	m := newModel([]string{"a", "b", "c", "d"}, Options{}, 2)
+	for i := 0; i < 3; i++ {
+		m.update(key{code: keyDown})
+	}
+	if m.cursor != 3 || m.offset != 2 {
+		t.Errorf("cursor, offset = %d, %d, want 3, 2", m.cursor, m.offset)
+	}
+
+	lines := m.render(80)
+	// Prompt, two items, status
+	if len(lines) != 4 || !strings.Contains(lines[2], "> ") || !strings.Contains(lines[2], "d") {
+		t.Errorf("render() = %q", lines)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		input string
+		width int
+		want  string
+	}{
+		{"alice", 10, "alice"},
+		{"alice", 5, "alice"},
+		{"alice", 4, "ali…"},
+		{"älice", 2, "ä…"},
+	}
+
+	for _, tt := range tests {
+		if got := truncate(tt.input, tt.width); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.input, tt.width, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Package picker provides a terminal fuzzy finder for selecting multiple items
+package picker
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+var (
+	// ErrCancelled is returned when the user cancels the selection
+	ErrCancelled = errors.New("selection cancelled")
+	// ErrNoTerminal is returned when there is no terminal to show the picker on
+	ErrNoTerminal = errors.New("no terminal available")
+)
+
+// maxHeight is the maximum number of items shown at once
+const maxHeight = 10
+
+// Options configures the picker
+type Options struct {
+	// Prompt is shown before the query
+	Prompt string
+	// Preview describes the highlighted item below the list, if set
+	Preview func(item string) string
+}
+
+// Select lets the user pick items on the terminal
+// Typing filters the items, Up/Down (or Ctrl-P/Ctrl-N) move, Space selects and Tab selects and
+// moves down, Enter confirms (the highlighted item if none is selected) and Esc or Ctrl-C cancels.
+// The picker is drawn on the controlling terminal, so it works even when standard input is redirected.
+func Select(items []string, opts Options) ([]string, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, ErrNoTerminal
+	}
+	defer tty.Close()
+
+	fd := int(tty.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, ErrNoTerminal
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up terminal: %w", err)
+	}
+	defer term.Restore(fd, state)
+
+	// Hide the cursor while picking
+	fmt.Fprint(tty, "\x1b[?25l")
+	defer fmt.Fprint(tty, "\x1b[?25h")
+
+	m := newModel(items, opts, min(len(items), maxHeight))
+	drawn := 0
+	buf := make([]byte, 256)
+	for {
+		width := 80
+		if w, _, err := term.GetSize(fd); err == nil {
+			width = w
+		}
+		drawn = redraw(tty, m.render(width), drawn)
+
+		n, err := tty.Read(buf)
+		if err != nil {
+			redraw(tty, nil, drawn)
+			return nil, fmt.Errorf("failed to read from terminal: %w", err)
+		}
+
+		for _, k := range parseKeys(buf[:n]) {
+			switch m.update(k) {
+			case actionConfirm:
+				redraw(tty, nil, drawn)
+				return m.result(), nil
+			case actionCancel:
+				redraw(tty, nil, drawn)
+				return nil, ErrCancelled
+			}
+		}
+	}
+}
+
+// redraw replaces the drawn lines with lines and returns how many lines are on screen
+func redraw(w io.Writer, lines []string, drawn int) int {
+	var b strings.Builder
+
+	// Go back to the first drawn line and clear everything below it
+	b.WriteString("\r")
+	if drawn > 1 {
+		fmt.Fprintf(&b, "\x1b[%dA", drawn-1)
+	}
+	b.WriteString("\x1b[J")
+
+	b.WriteString(strings.Join(lines, "\r\n"))
+	io.WriteString(w, b.String())
+	return len(lines)
+}
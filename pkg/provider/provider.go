@@ -0,0 +1,125 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrNotFound is returned when the user or group does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrRateLimited is returned when the provider's API rate limit has been exceeded
+	ErrRateLimited = errors.New("rate limit exceeded")
+)
+
+// Identity is a user account resolved by a provider
+type Identity struct {
+	ID    int64
+	Login string
+	Name  string
+	Email string
+}
+
+// NamePolicy selects how a co-author's name is written in trailers
+type NamePolicy string
+
+const (
+	// NameLogin uses the login (e.g. "alice")
+	NameLogin NamePolicy = "login"
+	// NameProfile uses the profile name (e.g. "Alice Smith")
+	NameProfile NamePolicy = "name"
+	// NameWithLogin uses the profile name followed by the login (e.g. "Alice Smith (alice)")
+	NameWithLogin NamePolicy = "name-login"
+)
+
+// ParseNamePolicy parses a name policy, defaulting to NameLogin for an empty value
+func ParseNamePolicy(value string) (NamePolicy, error) {
+	switch policy := NamePolicy(strings.ToLower(strings.TrimSpace(value))); policy {
+	case "":
+		return NameLogin, nil
+	case NameLogin, NameProfile, NameWithLogin:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid name format '%s' (expected login, name or name-login)", value)
+	}
+}
+
+// DisplayName returns the name to use in trailers according to policy
+// Falls back to the login when the profile name is empty.
+func (i Identity) DisplayName(policy NamePolicy) string {
+	name := strings.TrimSpace(i.Name)
+	if name == "" || name == i.Login {
+		return i.Login
+	}
+
+	switch policy {
+	case NameProfile:
+		return name
+	case NameWithLogin:
+		return fmt.Sprintf("%s (%s)", name, i.Login)
+	default:
+		return i.Login
+	}
+}
+
+// Provider resolves handles on a code hosting service into identities
+type Provider interface {
+	// Name returns the name used to select the provider (e.g. "github" in "github:alice")
+	Name() string
+	// Resolve gets the identity for a handle
+	Resolve(ctx context.Context, handle string) (*Identity, error)
+	// Search finds users matching query
+	Search(ctx context.Context, query string) ([]Identity, error)
+	// ListMembers lists the members of a group, such as an organization or team
+	ListMembers(ctx context.Context, group string) ([]Identity, error)
+}
+
+// handlePattern matches the "provider:handle" syntax
+var handlePattern = regexp.MustCompile(`^([a-z]+):(.+)$`)
+
+// ParseHandle splits "provider:handle" into its parts
+// The provider name is empty when the handle has no provider prefix.
+func ParseHandle(spec string) (name, handle string) {
+	if m := handlePattern.FindStringSubmatch(strings.TrimSpace(spec)); m != nil {
+		return m[1], m[2]
+	}
+	return "", strings.TrimSpace(spec)
+}
+
+// FormatCoAuthor creates a Co-Authored-By format string
+// from username and email address
+func FormatCoAuthor(username, email string) string {
+	return fmt.Sprintf("%s <%s>", username, email)
+}
+
+// DoJSON sends req and decodes a successful JSON response into v
+// 404 and 429 responses are reported as ErrNotFound and ErrRateLimited.
+func DoJSON(ctx context.Context, client *http.Client, req *http.Request, v any) (*http.Response, error) {
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return resp, ErrNotFound
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return resp, ErrRateLimited
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return resp, fmt.Errorf("unexpected response %s from %s", resp.Status, req.URL.Redacted())
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return resp, fmt.Errorf("failed to decode response from %s: %w", req.URL.Redacted(), err)
+	}
+	return resp, nil
+}
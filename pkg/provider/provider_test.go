@@ -0,0 +1,173 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseHandle(t *testing.T) {
+	tests := []struct {
+		name       string
+		spec       string
+		wantName   string
+		wantHandle string
+	}{
+		{
+			name:       "Bare handle",
+			spec:       "alice",
+			wantName:   "",
+			wantHandle: "alice",
+		},
+		{
+			name:       "Provider prefix",
+			spec:       "gitlab:alice",
+			wantName:   "gitlab",
+			wantHandle: "alice",
+		},
+		{
+			name:       "Surrounding whitespace",
+			spec:       " gitea:bob ",
+			wantName:   "gitea",
+			wantHandle: "bob",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotName, gotHandle := ParseHandle(tt.spec)
+			if gotName != tt.wantName || gotHandle != tt.wantHandle {
+				t.Errorf("ParseHandle() = %v, %v, want %v, %v", gotName, gotHandle, tt.wantName, tt.wantHandle)
+			}
+		})
+	}
+}
+
+func TestFormatCoAuthor(t *testing.T) {
+	got := FormatCoAuthor("alice", "alice@example.com")
+	if want := "alice <alice@example.com>"; got != want {
+		t.Errorf("FormatCoAuthor() = %v, want %v", got, want)
+	}
+}
+
+func TestDoJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		wantErr    error
+	}{
+		{
+			name:       "Success",
+			statusCode: http.StatusOK,
+			body:       `{"login":"alice"}`,
+			wantErr:    nil,
+		},
+		{
+			name:       "Not found",
+			statusCode: http.StatusNotFound,
+			body:       `{}`,
+			wantErr:    ErrNotFound,
+		},
+		{
+			name:       "Rate limited",
+			statusCode: http.StatusTooManyRequests,
+			body:       `{}`,
+			wantErr:    ErrRateLimited,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			var v struct {
+				Login string `json:"login"`
+			}
+			_, err := DoJSON(context.Background(), http.DefaultClient, req, &v)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("DoJSON() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && v.Login != "alice" {
+				t.Errorf("DoJSON() decoded %+v", v)
+			}
+		})
+	}
+}
+
+func TestParseNamePolicy(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    NamePolicy
+		wantErr bool
+	}{
+		{value: "", want: NameLogin, wantErr: false},
+		{value: "login", want: NameLogin, wantErr: false},
+		{value: "Name", want: NameProfile, wantErr: false},
+		{value: "name-login", want: NameWithLogin, wantErr: false},
+		{value: "email", want: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseNamePolicy(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseNamePolicy(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseNamePolicy(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestIdentityDisplayName(t *testing.T) {
+	withName := Identity{Login: "alice", Name: "Alice Smith"}
+	withoutName := Identity{Login: "bob"}
+
+	tests := []struct {
+		name     string
+		identity Identity
+		policy   NamePolicy
+		want     string
+	}{
+		{
+			name:     "Login policy",
+			identity: withName,
+			policy:   NameLogin,
+			want:     "alice",
+		},
+		{
+			name:     "Profile name policy",
+			identity: withName,
+			policy:   NameProfile,
+			want:     "Alice Smith",
+		},
+		{
+			name:     "Name with login policy",
+			identity: withName,
+			policy:   NameWithLogin,
+			want:     "Alice Smith (alice)",
+		},
+		{
+			name:     "Empty profile name falls back to login",
+			identity: withoutName,
+			policy:   NameWithLogin,
+			want:     "bob",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.identity.DisplayName(tt.policy); got != tt.want {
+				t.Errorf("DisplayName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}